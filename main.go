@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -15,6 +16,12 @@ import (
 	"slices"
 	"time"
 
+	"github.com/mikelu92/emailimport/pkg/bills"
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/output"
+	"github.com/mikelu92/emailimport/pkg/receipt"
+	"github.com/mikelu92/emailimport/pkg/reconcile"
 	"github.com/mikelu92/emailimport/provider"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -29,6 +36,13 @@ type Config struct {
 	Providers       []provider.ProviderConfig `yaml:"providers"`
 	Processed       string                    `yaml:"processedLabel"`
 	CredentialsFile string                    `yaml:"credentials"`
+	Journal         output.JournalConfig      `yaml:"journal"`
+	Reconcile       reconcile.Config          `yaml:"reconcile"`
+	Receipts        receipt.Config            `yaml:"receipts"`
+	Bills           bills.Config              `yaml:"bills"`
+	// Timezone is the IANA zone transaction dates are written in, the
+	// system's by default.
+	Timezone string `yaml:"timezone"`
 }
 
 // Retrieve a token, saves the token, then returns the generated client.
@@ -127,12 +141,104 @@ func saveToken(path string, token *oauth2.Token) {
 func main() {
 	ctx := context.Background()
 
-	flag.String("config", "config.yaml", "config for providers")
+	configPath := flag.String("config", "config.yaml", "config for providers")
+	format := flag.String("format", "journal", "output format: journal, jsonl, csv or hledger-csv")
+	outPath := flag.String("out", "", "file to append output to instead of stdout")
+	flag.Parse()
+
 	var c Config
-	err := ReadConfig("config.yaml", &c)
+	err := ReadConfig(*configPath, &c)
 	if err != nil {
 		log.Fatalf("Could not get config file")
 	}
+	for _, pr := range c.Providers {
+		if err := pr.Validate(); err != nil {
+			log.Fatalf("Invalid config: %v", err)
+		}
+	}
+	loc := time.Local
+	if c.Timezone != "" {
+		if loc, err = time.LoadLocation(c.Timezone); err != nil {
+			log.Fatalf("Invalid config: %v", err)
+		}
+	}
+	var tracker *bills.Tracker
+	if c.Bills.StateFile != "" {
+		tracker, err = bills.New(c.Bills)
+		if err != nil {
+			log.Fatalf("Unable to load bills state: %v", err)
+		}
+	}
+	// the bills report only reads the state file
+	if flag.Arg(0) == "bills" {
+		if tracker == nil {
+			log.Fatalf("bills: no bills stateFile in config")
+		}
+		if err := bills.Report(os.Stdout, tracker.Upcoming(time.Now().In(loc))); err != nil {
+			log.Fatalf("Unable to write bills report: %v", err)
+		}
+		return
+	}
+	out, err := output.Open(*format, *outPath, output.Options{Journal: c.Journal})
+	if err != nil {
+		log.Fatalf("Unable to create output: %v", err)
+	}
+	defer out.Close()
+	var rec *reconcile.Reconciler
+	if c.Reconcile.StateFile != "" {
+		rec, err = reconcile.New(c.Reconcile)
+		if err != nil {
+			log.Fatalf("Unable to load reconcile state: %v", err)
+		}
+	}
+	var receipts *receipt.Matcher
+	if c.Receipts.StateFile != "" {
+		receipts, err = receipt.New(c.Receipts)
+		if err != nil {
+			log.Fatalf("Unable to load receipt state: %v", err)
+		}
+	}
+	reconciled := func(t ledger.Transaction) error {
+		if rec == nil {
+			return out.Write(t)
+		}
+		ts, err := rec.Process(t)
+		if err != nil {
+			return err
+		}
+		for _, t := range ts {
+			if err := out.Write(t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	write := func(t ledger.Transaction) error {
+		if err := t.Check(); err != nil {
+			return err
+		}
+		t.Date = email.InLocation(t.Date, loc)
+		if tracker != nil {
+			if err := tracker.Add(t); err != nil {
+				return err
+			}
+		}
+		// bills aren't charges for receipts to itemise
+		if _, ok := t.Tags[bills.DueTag]; ok || receipts == nil {
+			return reconciled(t)
+		}
+		ts, err := receipts.Process(t, provider.IsReceipt(t.Source.Provider))
+		if err != nil {
+			return err
+		}
+		for _, t := range ts {
+			if err := reconciled(t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	b, err := os.ReadFile(c.CredentialsFile)
 	if err != nil {
 		log.Fatalf("Unable to read client secret file: %v", err)
@@ -148,7 +254,7 @@ func main() {
 	if err != nil {
 		log.Fatalf("Unable to retrieve Gmail client: %v", err)
 	}
-	args := os.Args[1:]
+	args := flag.Args()
 	if len(args) > 0 {
 		if args[0] == "labels" {
 			showLabels(srv)
@@ -174,29 +280,29 @@ func main() {
 			log.Fatalf("couldn't get msg %q\n", m.Id)
 			return
 		}
-		var p provider.Provider
-	find:
-		for _, id := range msg.LabelIds {
-			for _, pr := range c.Providers {
-				if id == pr.Label {
-					p = provider.Get(pr)
-					break find
-				}
-			}
+		pr, ok := c.providerFor(msg.LabelIds)
+		if !ok {
+			continue
 		}
+		p := provider.Get(pr)
 		if p == nil {
 			continue
 		}
-		t, err := p.GetTransaction(msg)
-		if err != nil {
+		ts, err := transactions(srv, p, msg)
+		if errors.Is(err, email.ErrNoTransaction) {
+			log.Printf("no transaction in msg %q for account %q, marking it processed\n", m.Id, p.GetAccount())
+		} else if err != nil {
 			log.Fatalf("unable to get transaction from email: %v", err)
-		}
-		if t == nil {
+		} else if len(ts) == 0 {
 			log.Printf("unrecognized transaction format for account %q, but will continue\n", p.GetAccount())
 			continue
 		}
-
-		fmt.Print(t.Print())
+		for _, t := range ts {
+			t.Source = source(msg, pr)
+			if err := write(t); err != nil {
+				log.Fatalf("couldn't write transaction for msg %q: %v", m.Id, err)
+			}
+		}
 		_, err = srv.Users.Messages.Modify(user, m.Id, &gmail.ModifyMessageRequest{AddLabelIds: []string{c.Processed}, RemoveLabelIds: []string{"UNREAD", "INBOX"}}).Do()
 		if err != nil {
 			log.Fatalf("couldn't modify message %q", m.Id)
@@ -214,17 +320,12 @@ func main() {
 		if err != nil {
 			log.Fatalf("could not get messages from thread")
 		}
-		var p provider.Provider
 		// only the first message in the thread will have our provider label ids
-	findTh:
-		for _, l := range ths.Messages[0].LabelIds {
-			for _, pr := range c.Providers {
-				if l == pr.Label {
-					p = provider.Get(pr)
-					break findTh
-				}
-			}
+		pr, ok := c.providerFor(ths.Messages[0].LabelIds)
+		if !ok {
+			continue
 		}
+		p := provider.Get(pr)
 		if p == nil {
 			continue
 		}
@@ -240,16 +341,21 @@ func main() {
 			if !unread {
 				continue
 			}
-			t, err := p.GetTransaction(m)
-			if err != nil {
+			ts, err := transactions(srv, p, m)
+			if errors.Is(err, email.ErrNoTransaction) {
+				log.Printf("no transaction in msg %q for account %q, marking it processed\n", m.Id, p.GetAccount())
+			} else if err != nil {
 				log.Fatalf("could not get transaction from thread message")
-			}
-			if t == nil {
+			} else if len(ts) == 0 {
 				log.Printf("unrecognized transaction format for account %q, but will continue\n", p.GetAccount())
 				continue
 			}
-
-			fmt.Print(t.Print())
+			for _, t := range ts {
+				t.Source = source(m, pr)
+				if err := write(t); err != nil {
+					log.Fatalf("couldn't write transaction for msg %q: %v", m.Id, err)
+				}
+			}
 
 			_, err = srv.Users.Messages.Modify(user, m.Id, &gmail.ModifyMessageRequest{AddLabelIds: []string{c.Processed}, RemoveLabelIds: []string{"UNREAD", "INBOX"}}).Do()
 			if err != nil {
@@ -259,6 +365,48 @@ func main() {
 	}
 }
 
+// providerFor returns the configured provider whose label is on the message.
+func (c *Config) providerFor(labels []string) (provider.ProviderConfig, bool) {
+	for _, id := range labels {
+		for _, pr := range c.Providers {
+			if id == pr.Label {
+				return pr, true
+			}
+		}
+	}
+	return provider.ProviderConfig{}, false
+}
+
+// transactions returns what p reads from msg: its transaction, or every
+// line of the statements attached to it for statement providers, whose
+// attachments are fetched first.
+func transactions(srv *gmail.Service, p provider.Provider, msg *gmail.Message) ([]ledger.Transaction, error) {
+	sp, ok := p.(provider.StatementProvider)
+	if !ok {
+		t, err := p.GetTransaction(msg)
+		if t == nil {
+			return nil, err
+		}
+		return []ledger.Transaction{*t}, err
+	}
+	fetch := func(messageID, attachmentID string) (*gmail.MessagePartBody, error) {
+		return srv.Users.Messages.Attachments.Get(user, messageID, attachmentID).Do()
+	}
+	if err := email.FetchAttachments(msg, fetch); err != nil {
+		return nil, fmt.Errorf("couldn't fetch attachments of msg %q: %w", msg.Id, err)
+	}
+	return sp.GetTransactions(msg)
+}
+
+// source describes the message a transaction was parsed from.
+func source(msg *gmail.Message, pr provider.ProviderConfig) ledger.Source {
+	s := ledger.Source{MessageID: msg.Id, Provider: pr.Type}
+	if msg.InternalDate != 0 {
+		s.Received = time.UnixMilli(msg.InternalDate)
+	}
+	return s
+}
+
 func getThreads(srv *gmail.Service) {
 	r, err := srv.Users.Threads.List(user).LabelIds("Label_1454095201736435186").Do()
 	if err != nil {
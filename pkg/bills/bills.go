@@ -0,0 +1,133 @@
+// Package bills keeps the bills parsed from statement emails and reports the
+// amounts coming due.
+package bills
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+)
+
+// DueTag is the tag holding the due date, as YYYY-MM-DD, of transactions
+// recording a bill.
+const DueTag = "due"
+
+type Config struct {
+	// StateFile keeps the bills between runs for the bills report. Bills
+	// are still written to the journal when it is empty.
+	StateFile string `yaml:"stateFile"`
+}
+
+// Bill is an amount owed to Payee by its due date.
+type Bill struct {
+	MessageID string    `json:"message_id,omitempty"`
+	Payee     string    `json:"payee"`
+	Account   string    `json:"account"`
+	Amount    string    `json:"amount"`
+	Due       time.Time `json:"due"`
+}
+
+type state struct {
+	Bills []Bill `json:"bills"`
+}
+
+// Tracker records bills in the state file.
+type Tracker struct {
+	conf  Config
+	state state
+}
+
+// New loads the state file, which doesn't need to exist yet.
+func New(conf Config) (*Tracker, error) {
+	tr := &Tracker{conf: conf}
+	b, err := os.ReadFile(conf.StateFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tr, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &tr.state); err != nil {
+		return nil, fmt.Errorf("invalid bills state %s: %w", conf.StateFile, err)
+	}
+	return tr, nil
+}
+
+// Add records t when it is a bill, one with a DueTag, replacing an earlier
+// bill from the same payee due the same day, and forgets bills due more than
+// 90 days before it. The state file is saved when t is a bill.
+func (tr *Tracker) Add(t ledger.Transaction) error {
+	ds, ok := t.Tags[DueTag]
+	if !ok {
+		return nil
+	}
+	due, err := time.ParseInLocation(time.DateOnly, ds, t.Date.Location())
+	if err != nil {
+		return fmt.Errorf("bill from %s: %w", t.Payee, err)
+	}
+	b := Bill{MessageID: t.Source.MessageID, Payee: t.Payee, Account: t.Account, Amount: t.Amount, Due: due}
+	cutoff := due.AddDate(0, 0, -90)
+	tr.state.Bills = slices.DeleteFunc(tr.state.Bills, func(o Bill) bool {
+		return o.Due.Before(cutoff) || o.Payee == b.Payee && o.Due.Equal(b.Due)
+	})
+	tr.state.Bills = append(tr.state.Bills, b)
+	return tr.save()
+}
+
+// Upcoming returns the bills due on or after the day of now, soonest first.
+func (tr *Tracker) Upcoming(now time.Time) []Bill {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var bs []Bill
+	for _, b := range tr.state.Bills {
+		if !b.Due.Before(today) {
+			bs = append(bs, b)
+		}
+	}
+	slices.SortStableFunc(bs, func(a, b Bill) int {
+		return a.Due.Compare(b.Due)
+	})
+	return bs
+}
+
+// Report writes bills as a table followed by their total in each commodity.
+func Report(w io.Writer, bs []Bill) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "due\tamount\t payee\t account\t\n")
+	var totals []ledger.Amount
+	for _, b := range bs {
+		fmt.Fprintf(tw, "%s\t%s\t %s\t %s\t\n", b.Due.Format(time.DateOnly), b.Amount, b.Payee, b.Account)
+		a, err := ledger.ParseAmount(b.Amount)
+		if err != nil {
+			return fmt.Errorf("bill from %s: %w", b.Payee, err)
+		}
+		i := slices.IndexFunc(totals, func(t ledger.Amount) bool { return t.Commodity == a.Commodity })
+		if i < 0 {
+			totals = append(totals, a)
+			continue
+		}
+		totals[i], _ = totals[i].Add(a)
+	}
+	for _, t := range totals {
+		fmt.Fprintf(tw, "total\t%s\t\t\t\n", t)
+	}
+	return tw.Flush()
+}
+
+func (tr *Tracker) save() error {
+	if tr.conf.StateFile == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(tr.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(tr.conf.StateFile, b, 0o600)
+}
@@ -0,0 +1,81 @@
+package bills
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+)
+
+func bill(payee, amount, due string, sent time.Time) ledger.Transaction {
+	return ledger.Transaction{
+		Payee:   payee,
+		Amount:  amount,
+		Account: "liabilities:payable",
+		Date:    sent,
+		Tags:    map[string]string{DueTag: due},
+	}
+}
+
+func TestTracker(t *testing.T) {
+	conf := Config{StateFile: filepath.Join(t.TempDir(), "bills.json")}
+	tr, err := New(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := time.Date(2025, 10, 13, 9, 0, 0, 0, time.UTC)
+	for _, b := range []ledger.Transaction{
+		bill("City Power", "$84.21", "2025-11-03", sent),
+		bill("Phone Co", "$45.00", "2025-10-20", sent),
+		bill("Water", "$30.00", "2025-10-01", sent),
+		// a reminder of the same bill replaces it
+		bill("City Power", "$84.25", "2025-11-03", sent.AddDate(0, 0, 7)),
+		{Payee: "not a bill", Amount: "$1.00", Date: sent},
+	} {
+		if err := tr.Add(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// reload from the state file
+	tr, err = New(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := tr.Upcoming(time.Date(2025, 10, 14, 18, 0, 0, 0, time.UTC))
+	if len(got) != 2 {
+		t.Fatalf("expected 2 upcoming bills, got %+v", got)
+	}
+	if got[0].Payee != "Phone Co" || got[1].Payee != "City Power" || got[1].Amount != "$84.25" {
+		t.Fatalf("unexpected upcoming bills %+v", got)
+	}
+
+	var b strings.Builder
+	if err := Report(&b, got); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"2025-10-20", "Phone Co", "2025-11-03", "$84.25", "total", "$129.25"} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("report missing %q:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestAddForgetsOldBills(t *testing.T) {
+	tr, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	if err := tr.Add(bill("Water", "$30.00", "2025-06-15", sent)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Add(bill("Water", "$31.00", "2025-10-15", sent.AddDate(0, 4, 0))); err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.state.Bills) != 1 || tr.state.Bills[0].Amount != "$31.00" {
+		t.Fatalf("expected only the recent bill, got %+v", tr.state.Bills)
+	}
+}
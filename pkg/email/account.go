@@ -0,0 +1,66 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var suffixExps = []*regexp.Regexp{
+	// "(...8719)", "…8719", "****8719", "XXXX8719"
+	regexp.MustCompile(`(?:\.{3}|…|\*{2,}|[xX]{2,})\s?(\d{4})\b`),
+	// "ending in 8719", "last 4 digits 8719", "Account # 8719"
+	regexp.MustCompile(`(?i)(?:ending(?: in)?|last (?:4|four)(?: digits)?(?: of)?|account(?: number)?|card(?: number)?)\s*(?:#|no\.?|is)?\s*:?\s*(\d{4})\b`),
+}
+
+// CardSuffix returns the last four digits of the card or account mentioned
+// in s, e.g. "Chase Freedom (...8719)" or "your card ending in 8719". Bare
+// four digit numbers are ignored since they are usually years.
+func CardSuffix(s string) (int, bool) {
+	for _, exp := range suffixExps {
+		if m := exp.FindStringSubmatch(s); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// ErrUnknownSuffix is returned by a strict Router for a card suffix that
+// isn't in its accounts.
+var ErrUnknownSuffix = errors.New("unknown card suffix")
+
+// Router picks the account of a transaction from the card suffix in the
+// email.
+type Router struct {
+	// Accounts maps card suffixes to accounts.
+	Accounts map[int]string
+	// Default is the account for emails without a suffix, and for unknown
+	// suffixes unless Strict is set. An empty Default skips them.
+	Default string
+	// Strict makes an unknown suffix an error instead of falling back to
+	// Default.
+	Strict bool
+}
+
+// Route returns the account for the card suffix in s, see CardSuffix, or ""
+// when the transaction should be skipped.
+func (r Router) Route(s string) (string, error) {
+	n, ok := CardSuffix(s)
+	if !ok {
+		return r.Default, nil
+	}
+	return r.Lookup(n)
+}
+
+// Lookup returns the account for a card suffix.
+func (r Router) Lookup(suffix int) (string, error) {
+	if act, ok := r.Accounts[suffix]; ok {
+		return act, nil
+	}
+	if r.Strict {
+		return "", fmt.Errorf("%w %04d", ErrUnknownSuffix, suffix)
+	}
+	return r.Default, nil
+}
@@ -0,0 +1,55 @@
+package email
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCardSuffix(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   int
+		wantOK bool
+	}{
+		{in: "Chase Freedom Visa (...8719)", want: 8719, wantOK: true},
+		{in: "Chase Freedom Visa (…8719)", want: 8719, wantOK: true},
+		{in: "your Venture card ending in 0042 on March 3, 2025", want: 42, wantOK: true},
+		{in: "Account ending 1234", want: 1234, wantOK: true},
+		{in: "Card number: XXXX XXXX XXXX 5678", want: 5678, wantOK: true},
+		{in: "Card ****9012 was charged", want: 9012, wantOK: true},
+		{in: "last 4 digits of your card: 3456", want: 3456, wantOK: true},
+		{in: "On March 3, 2025, at WALMART", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := CardSuffix(tt.in)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("CardSuffix(%q) = %d, %v, want %d, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRouter(t *testing.T) {
+	r := Router{Accounts: map[int]string{1111: "liabilities:venture", 2222: "liabilities:quicksilver"}, Default: "liabilities:capitalone"}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "card ending in 2222", want: "liabilities:quicksilver"},
+		{in: "card ending in 3333", want: "liabilities:capitalone"},
+		{in: "no card mentioned", want: "liabilities:capitalone"},
+	}
+	for _, tt := range tests {
+		got, err := r.Route(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("Route(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+
+	r.Strict = true
+	if _, err := r.Route("card ending in 3333"); !errors.Is(err, ErrUnknownSuffix) {
+		t.Errorf("expected ErrUnknownSuffix, got %v", err)
+	}
+	if got, err := r.Route("no card mentioned"); err != nil || got != "liabilities:capitalone" {
+		t.Errorf("Route() without a suffix = %q, %v", got, err)
+	}
+}
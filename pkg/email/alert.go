@@ -0,0 +1,144 @@
+package email
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+var alertAmount = regexp.MustCompile(`\$\d{1,3}(?:,\d{3})*\.\d{2}`)
+
+// Alert is an email template of a card issuer or bank: what it is
+// recognised by and how the transaction it announces is booked.
+type Alert struct {
+	// Exp matches a sentence of a plain text alert, capturing amt (or amt2
+	// in an alternative), payee and date.
+	Exp *regexp.Regexp
+	// Subject lists phrases, one of which the lower-cased subject of an HTML
+	// alert contains.
+	Subject []string
+	// Amount lists the labels of the amount in an HTML alert's table of
+	// details. A table without any of them is left to the next template.
+	Amount  []string
+	Receive bool
+	Status  ledger.Status
+	// Payee is used when the alert doesn't name one.
+	Payee string
+}
+
+// Alerts are the templates of one issuer, tried in order.
+type Alerts struct {
+	Templates []Alert
+	// Payee and Date list the labels of the payee and date in the table of
+	// details of HTML alerts.
+	Payee []string
+	Date  []string
+	// Layouts are the time layouts of dates in the alerts.
+	Layouts []string
+}
+
+// FromText returns the transaction on account described by the first
+// template whose Exp matches the text of msg, or nil.
+func (as Alerts) FromText(msg *gmail.Message, account string) (*ledger.Transaction, error) {
+	text := Text(msg)
+	for _, a := range as.Templates {
+		match := a.Exp.FindStringSubmatch(text)
+		if len(match) == 0 {
+			continue
+		}
+		t := ledger.Transaction{Account: account, IsReceive: a.Receive, Status: a.Status, Payee: a.Payee}
+		var date string
+		for i, name := range a.Exp.SubexpNames() {
+			if match[i] == "" {
+				continue
+			}
+			switch strings.TrimSuffix(name, "2") {
+			case "amt":
+				t.Amount = match[i]
+			case "payee":
+				t.Payee = strings.TrimSpace(match[i])
+			case "date":
+				date = match[i]
+			}
+		}
+		d, err := as.date(msg, date)
+		if err != nil {
+			return nil, err
+		}
+		t.Date = d
+		return &t, nil
+	}
+	return nil, nil
+}
+
+// FromTable returns the transaction on account described by the first
+// template whose subject phrase and amount label the HTML body of msg has,
+// or nil.
+func (as Alerts) FromTable(msg *gmail.Message, account string) (*ledger.Transaction, error) {
+	subject := strings.ToLower(header(msg, "Subject"))
+	var fields Fields
+	for _, a := range as.Templates {
+		if !a.matches(subject) {
+			continue
+		}
+		if fields == nil {
+			body, err := HTML(msg)
+			if err != nil || body == nil {
+				return nil, err
+			}
+			if fields, err = TableFields(bytes.NewReader(body)); err != nil {
+				return nil, err
+			}
+		}
+		amt := alertAmount.FindString(fields.Get(a.Amount...))
+		if amt == "" {
+			continue
+		}
+		t := ledger.Transaction{Account: account, Amount: amt, IsReceive: a.Receive, Status: a.Status, Payee: a.Payee}
+		if payee := fields.Get(as.Payee...); payee != "" {
+			t.Payee = payee
+		}
+		if t.Payee == "" {
+			return nil, nil
+		}
+		d, err := as.date(msg, fields.Get(as.Date...))
+		if err != nil {
+			return nil, err
+		}
+		t.Date = d
+		return &t, nil
+	}
+	return nil, nil
+}
+
+func (a Alert) matches(subject string) bool {
+	for _, phrase := range a.Subject {
+		if strings.Contains(subject, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// date parses the date in the alert, falling back to when the email was
+// sent since alerts go out right away.
+func (as Alerts) date(msg *gmail.Message, s string) (time.Time, error) {
+	if s != "" {
+		// abbreviated months may end in a period, e.g. "Sep. 5, 2025"
+		s = strings.Replace(s, ".", "", 1)
+		for _, layout := range as.Layouts {
+			if d, err := time.Parse(layout, s); err == nil {
+				return d, nil
+			}
+		}
+	}
+	if d := Sent(msg); !d.IsZero() {
+		return d, nil
+	}
+	return time.Time{}, errors.New("alert email has no date")
+}
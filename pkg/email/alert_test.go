@@ -0,0 +1,113 @@
+package email
+
+import (
+	"encoding/base64"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestAlertsFromText(t *testing.T) {
+	alerts := Alerts{
+		Layouts: []string{"Jan 2, 2006"},
+		Templates: []Alert{
+			{Exp: regexp.MustCompile(`payment of (?P<amt>\$[\d.]+) (?:was|has been) posted(?: on (?P<date>\w+\.? \d+, \d{4}))?`), Receive: true, Status: ledger.Cleared, Payee: "Card payment"},
+			{Exp: regexp.MustCompile(`charge of (?P<amt>\$[\d.]+) at (?P<payee>[A-Z ]+)`), Status: ledger.Pending},
+		},
+	}
+	tests := []struct {
+		name string
+		body string
+		want *ledger.Transaction
+	}{
+		{
+			name: "dated",
+			body: "Your payment of $50.00 was posted on Sep. 5, 2025.",
+			want: &ledger.Transaction{Account: "card", Amount: "$50.00", IsReceive: true, Status: ledger.Cleared, Payee: "Card payment", Date: time.Date(2025, 9, 5, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "sent date",
+			body: "A charge of $4.50 at BLUE BOTTLE was approved.",
+			want: &ledger.Transaction{Account: "card", Amount: "$4.50", Status: ledger.Pending, Payee: "BLUE BOTTLE", Date: time.Date(2025, 5, 6, 8, 15, 0, 0, time.UTC)},
+		},
+		{name: "no template", body: "Your statement is ready."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := alerts.FromText(emailtest.Text("Tue, 6 May 2025 08:15:00 +0000", "", tt.body), "card")
+			if err != nil {
+				t.Fatalf("FromText returned error: %v", err)
+			}
+			if got != nil {
+				got.Date = got.Date.UTC()
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromText() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertsFromTable(t *testing.T) {
+	alerts := Alerts{
+		Payee:   []string{"Merchant"},
+		Date:    []string{"Date"},
+		Layouts: []string{"01/02/2006"},
+		Templates: []Alert{
+			{Subject: []string{"payment"}, Amount: []string{"Payment amount"}, Receive: true, Status: ledger.Cleared, Payee: "Card payment"},
+			{Subject: []string{"card alert"}, Amount: []string{"Purchase amount"}, Status: ledger.Pending},
+		},
+	}
+	message := func(subject, rows string) *gmail.Message {
+		doc := "<html><body><table>" + rows + "</table></body></html>"
+		return &gmail.Message{
+			Payload: &gmail.MessagePart{
+				Headers: []*gmail.MessagePartHeader{
+					{Name: "Subject", Value: subject},
+					{Name: "Content-Type", Value: "text/html; charset=UTF-8"},
+				},
+				Body: &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(doc))},
+			},
+		}
+	}
+	tests := []struct {
+		name string
+		msg  *gmail.Message
+		want *ledger.Transaction
+	}{
+		{
+			name: "payment",
+			msg:  message("Your card payment alert", "<tr><td>Payment amount</td><td>$80.00</td></tr><tr><td>Date</td><td>05/06/2025</td></tr>"),
+			want: &ledger.Transaction{Account: "card", Amount: "$80.00", IsReceive: true, Status: ledger.Cleared, Payee: "Card payment", Date: time.Date(2025, 5, 6, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "amount label picks the template",
+			msg:  message("Wells Fargo card alert", "<tr><td>Purchase amount</td><td>$12.00</td></tr><tr><td>Merchant</td><td>CAFE</td></tr><tr><td>Date</td><td>05/05/2025</td></tr>"),
+			want: &ledger.Transaction{Account: "card", Amount: "$12.00", Status: ledger.Pending, Payee: "CAFE", Date: time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "payment reminder without an amount label",
+			msg:  message("Your payment is due", "<tr><td>Minimum due</td><td>$35.00</td></tr>"),
+		},
+		{
+			name: "unrelated subject",
+			msg:  message("Your statement is ready", "<tr><td>Payment amount</td><td>$80.00</td></tr>"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := alerts.FromTable(tt.msg, "card")
+			if err != nil {
+				t.Fatalf("FromTable returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromTable() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package email
+
+import (
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// Attachment is a file attached to an email.
+type Attachment struct {
+	Filename string
+	MimeType string
+	Data     []byte
+}
+
+// AttachmentFetcher gets the body of an attachment Gmail stores apart from
+// its message, as srv.Users.Messages.Attachments.Get does.
+type AttachmentFetcher func(messageID, attachmentID string) (*gmail.MessagePartBody, error)
+
+// FetchAttachments fills in the body of every attachment of msg that Gmail
+// only references by ID, so that Attachments can read them.
+func FetchAttachments(msg *gmail.Message, fetch AttachmentFetcher) error {
+	if msg == nil || msg.Payload == nil {
+		return nil
+	}
+	var walk func(p *gmail.MessagePart) error
+	walk = func(p *gmail.MessagePart) error {
+		if p.Filename != "" && p.Body != nil && p.Body.Data == "" && p.Body.AttachmentId != "" {
+			body, err := fetch(msg.Id, p.Body.AttachmentId)
+			if err != nil {
+				return err
+			}
+			p.Body.Data = body.Data
+		}
+		for _, c := range p.Parts {
+			if err := walk(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(msg.Payload)
+}
+
+// Attachments returns the decoded files attached to msg, the parts with a
+// filename. Attachments that weren't fetched are skipped.
+func Attachments(msg *gmail.Message) []Attachment {
+	if msg == nil || msg.Payload == nil {
+		return nil
+	}
+	var as []Attachment
+	var walk func(p *gmail.MessagePart)
+	walk = func(p *gmail.MessagePart) {
+		if p.Filename != "" && p.Body != nil && p.Body.Data != "" {
+			if data, err := decodeBase64(p.Body.Data); err == nil {
+				as = append(as, Attachment{Filename: p.Filename, MimeType: strings.ToLower(contentType(p)), Data: data})
+			}
+		}
+		for _, c := range p.Parts {
+			walk(c)
+		}
+	}
+	walk(msg.Payload)
+	return as
+}
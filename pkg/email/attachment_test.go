@@ -0,0 +1,45 @@
+package email
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestAttachments(t *testing.T) {
+	csv := base64.URLEncoding.EncodeToString([]byte("Date,Amount\n"))
+	msg := &gmail.Message{
+		Id: "msg1",
+		Payload: &gmail.MessagePart{
+			MimeType: "multipart/mixed",
+			Parts: []*gmail.MessagePart{
+				{MimeType: "text/plain", Body: &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte("Your statement is attached."))}},
+				{MimeType: "text/csv", Filename: "inline.csv", Body: &gmail.MessagePartBody{Data: csv}},
+				{MimeType: "application/pdf", Filename: "statement.pdf", Body: &gmail.MessagePartBody{AttachmentId: "att1"}},
+			},
+		},
+	}
+	if as := Attachments(msg); len(as) != 1 || as[0].Filename != "inline.csv" {
+		t.Fatalf("expected only the inline attachment before fetching, got %+v", as)
+	}
+
+	var fetched []string
+	err := FetchAttachments(msg, func(messageID, attachmentID string) (*gmail.MessagePartBody, error) {
+		fetched = append(fetched, messageID+"/"+attachmentID)
+		return &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte("%PDF-1.4"))}, nil
+	})
+	if err != nil {
+		t.Fatalf("FetchAttachments returned error: %v", err)
+	}
+	if len(fetched) != 1 || fetched[0] != "msg1/att1" {
+		t.Fatalf("expected att1 of msg1 to be fetched, got %v", fetched)
+	}
+	as := Attachments(msg)
+	if len(as) != 2 {
+		t.Fatalf("expected 2 attachments, got %+v", as)
+	}
+	if as[1].Filename != "statement.pdf" || as[1].MimeType != "application/pdf" || string(as[1].Data) != "%PDF-1.4" {
+		t.Fatalf("unexpected attachment %+v", as[1])
+	}
+}
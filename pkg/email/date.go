@@ -0,0 +1,191 @@
+package email
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// zones maps the timezone abbreviations found in US alert emails to their
+// UTC offset in hours. time.Parse only knows the abbreviations of the local
+// zone and takes any other as UTC.
+var zones = map[string]int{
+	"UT": 0, "UTC": 0, "GMT": 0, "Z": 0,
+	"EST": -5, "EDT": -4,
+	"CST": -6, "CDT": -5,
+	"MST": -7, "MDT": -6,
+	"PST": -8, "PDT": -7,
+	"AKST": -9, "AKDT": -8,
+	"HST": -10,
+}
+
+// regions are the generic abbreviations that follow daylight saving time,
+// with the standard offset used when the zone database is unavailable.
+var regions = map[string]struct {
+	name   string
+	offset int
+}{
+	"ET": {"America/New_York", -5},
+	"CT": {"America/Chicago", -6},
+	"MT": {"America/Denver", -7},
+	"PT": {"America/Los_Angeles", -8},
+}
+
+// fixZone moves a time parsed with an unknown zone abbreviation, which
+// time.Parse leaves at UTC, to the zone the abbreviation names.
+func fixZone(t time.Time) time.Time {
+	name, offset := t.Zone()
+	if offset != 0 {
+		return t
+	}
+	if h, ok := zones[name]; ok {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.FixedZone(name, h*3600))
+	}
+	if r, ok := regions[name]; ok {
+		loc, err := time.LoadLocation(r.name)
+		if err != nil {
+			loc = time.FixedZone(name, r.offset*3600)
+		}
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+	}
+	return t
+}
+
+// dateLayouts are tried by ParseDate for dates net/mail doesn't accept.
+var dateLayouts = []string{
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"Monday, 02-Jan-06 15:04:05 MST",
+	"Mon, 2 Jan 06 15:04:05 -0700",
+	"Mon Jan 2 15:04:05 MST 2006",
+	"Mon Jan 2 15:04:05 2006",
+	time.RFC3339,
+	"2006-01-02 15:04:05 -0700",
+}
+
+var (
+	comments   = regexp.MustCompile(`\([^()]*\)`)
+	gmtOffset  = regexp.MustCompile(`\b(?:GMT|UTC)([+-]\d{2}):?(\d{2})$`)
+	dotWeekday = regexp.MustCompile(`^([A-Za-z]{3})\.,?`)
+)
+
+// ParseDate parses the date of an email header in any of the variants seen
+// in the wild: RFC 5322 with or without the weekday, seconds or a trailing
+// "(UTC)" comment, zone abbreviations such as "EST", "GMT+0000" offsets,
+// two digit years and the asctime layout of some mailers.
+func ParseDate(s string) (time.Time, error) {
+	s = strings.Join(strings.Fields(comments.ReplaceAllString(s, " ")), " ")
+	s = gmtOffset.ReplaceAllString(s, "$1$2")
+	s = dotWeekday.ReplaceAllString(s, "$1,")
+	if d, err := mail.ParseDate(s); err == nil {
+		return fixZone(d), nil
+	}
+	for _, layout := range dateLayouts {
+		if d, err := time.Parse(layout, s); err == nil {
+			return fixZone(d), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// Sent returns the time msg was sent according to its Date header, falling
+// back to the time Gmail received it. It is zero when neither is known.
+func Sent(msg *gmail.Message) time.Time {
+	if d, err := ParseDate(header(msg, "Date")); err == nil {
+		return d
+	}
+	return internalDate(msg)
+}
+
+// Received returns the time the email reached the last mail server, from the
+// topmost Received header, falling back to the time Gmail received it.
+func Received(msg *gmail.Message) time.Time {
+	// the date follows the last ";", the "from ... by ... with ..." part
+	// before it may contain semicolons of its own
+	h := header(msg, "Received")
+	if i := strings.LastIndex(h, ";"); i >= 0 {
+		if d, err := ParseDate(h[i+1:]); err == nil {
+			return d
+		}
+	}
+	return internalDate(msg)
+}
+
+func internalDate(msg *gmail.Message) time.Time {
+	if msg.InternalDate != 0 {
+		return time.UnixMilli(msg.InternalDate)
+	}
+	return time.Time{}
+}
+
+// header returns the value of the first header of msg called name.
+func header(msg *gmail.Message, name string) string {
+	if msg.Payload == nil {
+		return ""
+	}
+	for _, h := range msg.Payload.Headers {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+// InLocation converts t to loc for formatting. Dates without a time of day,
+// parsed as midnight UTC, keep their calendar day.
+func InLocation(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	if t.Location() == time.UTC && t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+	return t.In(loc)
+}
+
+// PartialDate parses value, a date without a year such as "12/31 23:10 EST",
+// with layout and gives it the year that makes it the closest date not after
+// ref, usually the time the email was sent. A timezone abbreviation in value
+// is honoured; without one the date is in ref's location.
+func PartialDate(layout, value string, ref time.Time) (time.Time, error) {
+	loc := ref.Location()
+	d, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	d = fixZone(d)
+	// an alert may be dated slightly after the email when clocks disagree
+	limit := ref.Add(24 * time.Hour)
+	for year := ref.Year(); year >= ref.Year()-4; year-- {
+		c := time.Date(year, d.Month(), d.Day(), d.Hour(), d.Minute(), d.Second(), d.Nanosecond(), d.Location())
+		// February 29th only exists in leap years
+		if c.Day() == d.Day() && !c.After(limit) {
+			return c, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no year for %q before %s", value, ref.Format(time.DateOnly))
+}
+
+// UpcomingDate is PartialDate for dates that are usually after ref, such as
+// the due date of a bill: it gives value the year that makes it the first
+// date no more than a month before ref.
+func UpcomingDate(layout, value string, ref time.Time) (time.Time, error) {
+	d, err := time.ParseInLocation(layout, value, ref.Location())
+	if err != nil {
+		return time.Time{}, err
+	}
+	// a bill may be sent, or read, after it is due
+	limit := ref.AddDate(0, -1, 0)
+	for year := ref.Year() - 1; year <= ref.Year()+4; year++ {
+		c := time.Date(year, d.Month(), d.Day(), d.Hour(), d.Minute(), d.Second(), d.Nanosecond(), d.Location())
+		if c.Day() == d.Day() && !c.Before(limit) {
+			return c, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no year for %q after %s", value, ref.Format(time.DateOnly))
+}
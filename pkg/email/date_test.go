@@ -0,0 +1,138 @@
+package email
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestPartialDate(t *testing.T) {
+	ref := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		layout string
+		value  string
+		want   time.Time
+	}{
+		{layout: "01/02 15:04 MST", value: "01/02 09:30 EST", want: time.Date(2026, 1, 2, 14, 30, 0, 0, time.UTC)},
+		{layout: "01/02 15:04 MST", value: "12/31 23:10 PST", want: time.Date(2026, 1, 1, 7, 10, 0, 0, time.UTC)},
+		{layout: "01/02 15:04 MST", value: "08/16 18:05 CDT", want: time.Date(2025, 8, 16, 23, 5, 0, 0, time.UTC)},
+		{layout: "Jan 2", value: "Jan 3", want: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{layout: "Jan 2", value: "Jan 5", want: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{layout: "Jan 2", value: "Feb 29", want: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := PartialDate(tt.layout, tt.value, ref)
+		if err != nil {
+			t.Fatalf("PartialDate(%q) returned error: %v", tt.value, err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("PartialDate(%q) = %v, want %v", tt.value, got.UTC(), tt.want)
+		}
+	}
+	if _, err := PartialDate("01/02", "13/45", ref); err == nil {
+		t.Errorf("expected an error for an invalid date")
+	}
+}
+
+func TestUpcomingDate(t *testing.T) {
+	ref := time.Date(2025, 12, 10, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{value: "Jan 3", want: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{value: "Dec 28", want: time.Date(2025, 12, 28, 0, 0, 0, 0, time.UTC)},
+		{value: "Nov 30", want: time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC)},
+		{value: "Nov 3", want: time.Date(2026, 11, 3, 0, 0, 0, 0, time.UTC)},
+		{value: "Feb 29", want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := UpcomingDate("Jan 2", tt.value, ref)
+		if err != nil {
+			t.Fatalf("UpcomingDate(%q) returned error: %v", tt.value, err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("UpcomingDate(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSent(t *testing.T) {
+	msg := &gmail.Message{
+		InternalDate: time.Date(2025, 8, 17, 10, 0, 0, 0, time.UTC).UnixMilli(),
+		Payload: &gmail.MessagePart{Headers: []*gmail.MessagePartHeader{
+			{Name: "Date", Value: "Sun, 17 Aug 2025 05:50:14 EDT"},
+		}},
+	}
+	if got, want := Sent(msg), time.Date(2025, 8, 17, 9, 50, 14, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Sent() = %v, want %v", got.UTC(), want)
+	}
+	msg.Payload.Headers = nil
+	if got, want := Sent(msg), time.Date(2025, 8, 17, 10, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Sent() without a Date header = %v, want %v", got.UTC(), want)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2025, 8, 17, 9, 50, 14, 0, time.UTC)
+	tests := []string{
+		"Sun, 17 Aug 2025 09:50:14 +0000 (UTC)",
+		"Sun, 17 Aug 2025 09:50:14 +0000",
+		"17 Aug 2025 09:50:14 +0000",
+		"Sun, 17 Aug 2025 05:50:14 -0400 (EDT)",
+		"Sun, 17 Aug 2025 05:50:14 EDT",
+		"Sun, 17 Aug 2025 02:50:14 PDT",
+		"Sun, 17 Aug 2025 09:50:14 GMT",
+		"Sun, 17 Aug 2025 09:50:14 GMT+00:00",
+		"Sun,  17  Aug 2025\r\n 09:50:14 +0000",
+		"Sun, 17 Aug 25 09:50:14 +0000",
+		"Sunday, 17-Aug-25 09:50:14 UTC",
+		"Sun Aug 17 09:50:14 UTC 2025",
+		"2025-08-17T09:50:14Z",
+	}
+	for _, s := range tests {
+		got, err := ParseDate(s)
+		if err != nil {
+			t.Errorf("ParseDate(%q) returned error: %v", s, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("ParseDate(%q) = %v, want %v", s, got.UTC(), want)
+		}
+	}
+	if got, err := ParseDate("Sun, 17 Aug 2025 09:50 +0000"); err != nil || !got.Equal(want.Truncate(time.Minute)) {
+		t.Errorf("ParseDate without seconds = %v, %v", got, err)
+	}
+	if _, err := ParseDate("yesterday"); err == nil {
+		t.Errorf("expected an error for an invalid date")
+	}
+}
+
+func TestReceived(t *testing.T) {
+	msg := &gmail.Message{
+		Payload: &gmail.MessagePart{Headers: []*gmail.MessagePartHeader{
+			{Name: "Received", Value: "by 2002:a05:6a10:1234 with SMTP id x; Sun, 17 Aug 2025 02:50:16 -0700 (PDT)"},
+			{Name: "Received", Value: "from mail.target.com; Sun, 17 Aug 2025 09:50:14 +0000"},
+		}},
+	}
+	if got, want := Received(msg), time.Date(2025, 8, 17, 9, 50, 16, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Received() = %v, want %v", got.UTC(), want)
+	}
+	msg.Payload.Headers = []*gmail.MessagePartHeader{{Name: "Received", Value: "by localhost"}}
+	if got := Received(msg); !got.IsZero() {
+		t.Errorf("Received() without a date = %v", got)
+	}
+}
+
+func TestInLocation(t *testing.T) {
+	ny := time.FixedZone("EDT", -4*3600)
+	d := InLocation(time.Date(2025, 8, 17, 2, 30, 0, 0, time.UTC), ny)
+	if d.Format("2006-01-02 15:04") != "2025-08-16 22:30" {
+		t.Errorf("InLocation() = %v", d)
+	}
+	d = InLocation(time.Date(2025, 8, 17, 0, 0, 0, 0, time.UTC), ny)
+	if d.Format("2006-01-02") != "2025-08-17" {
+		t.Errorf("InLocation() of a date = %v", d)
+	}
+}
@@ -0,0 +1,66 @@
+// Package emailtest builds Gmail messages for provider tests.
+package emailtest
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// Text returns a message with a single text/plain body, sent at date, an
+// RFC 5322 date such as "Tue, 6 May 2025 08:15:00 +0000". The Subject
+// header is left out when subject is empty.
+func Text(date, subject, body string) *gmail.Message {
+	return &gmail.Message{
+		Payload: &gmail.MessagePart{
+			Headers: headers(subject, date, "text/plain; charset=\"UTF-8\""),
+			Body:    &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(body))},
+		},
+	}
+}
+
+// Multipart is Text with the body in the text/plain part of a
+// multipart/alternative message, the way most alerts are sent.
+func Multipart(date, subject, body string) *gmail.Message {
+	return &gmail.Message{
+		Payload: &gmail.MessagePart{
+			Headers: headers(subject, date, "multipart/alternative; boundary=abc"),
+			Parts: []*gmail.MessagePart{
+				{
+					Headers: []*gmail.MessagePartHeader{{Name: "Content-Type", Value: "text/plain; charset=\"UTF-8\""}},
+					Body:    &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(body))},
+				},
+			},
+		},
+	}
+}
+
+// HTML returns a message whose text/html body is the file testdata/fixture
+// of the package under test.
+func HTML(t testing.TB, date, subject, fixture string) *gmail.Message {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return &gmail.Message{
+		Payload: &gmail.MessagePart{
+			Headers: headers(subject, date, "text/html; charset=UTF-8"),
+			Body:    &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString(body)},
+		},
+	}
+}
+
+func headers(subject, date, contentType string) []*gmail.MessagePartHeader {
+	var hs []*gmail.MessagePartHeader
+	if subject != "" {
+		hs = append(hs, &gmail.MessagePartHeader{Name: "Subject", Value: subject})
+	}
+	return append(hs,
+		&gmail.MessagePartHeader{Name: "Date", Value: date},
+		&gmail.MessagePartHeader{Name: "Content-Type", Value: contentType},
+	)
+}
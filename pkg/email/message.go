@@ -0,0 +1,126 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/net/html"
+	"google.golang.org/api/gmail/v1"
+)
+
+// ErrNoTransaction is returned by providers for emails they recognise that
+// don't record any money moving, such as declined charges. They are marked
+// processed without writing a transaction.
+var ErrNoTransaction = errors.New("email has no transaction")
+
+// Text returns the subject and the text of every text/plain and text/html
+// part of msg, separated by newlines, for searches that don't depend on the
+// layout of the email.
+func Text(msg *gmail.Message) string {
+	if msg == nil || msg.Payload == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, h := range msg.Payload.Headers {
+		if h.Name == "Subject" {
+			b.WriteString(h.Value + "\n")
+		}
+	}
+	var walk func(p *gmail.MessagePart)
+	walk = func(p *gmail.MessagePart) {
+		if p.Body != nil && p.Body.Data != "" {
+			data, err := decodeBase64(p.Body.Data)
+			ct := contentType(p)
+			switch {
+			case err != nil:
+			case strings.HasPrefix(ct, "text/html") || ct == "" && bytes.Contains(data, []byte("<html")):
+				b.WriteString(htmlText(data) + "\n")
+			case strings.HasPrefix(ct, "text/") || ct == "":
+				b.Write(data)
+				b.WriteString("\n")
+			}
+		}
+		for _, c := range p.Parts {
+			walk(c)
+		}
+	}
+	walk(msg.Payload)
+	return b.String()
+}
+
+// HTML returns the decoded body of the first text/html part of msg, or nil
+// when it has none.
+func HTML(msg *gmail.Message) ([]byte, error) {
+	if msg == nil {
+		return nil, nil
+	}
+	var walk func(p *gmail.MessagePart) *gmail.MessagePart
+	walk = func(p *gmail.MessagePart) *gmail.MessagePart {
+		if p == nil {
+			return nil
+		}
+		if strings.HasPrefix(contentType(p), "text/html") && p.Body != nil && p.Body.Data != "" {
+			return p
+		}
+		for _, c := range p.Parts {
+			if found := walk(c); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	part := walk(msg.Payload)
+	if part == nil {
+		return nil, nil
+	}
+	return decodeBase64(part.Body.Data)
+}
+
+// SenderName returns the display name of the From header of msg, such as
+// "Comcast" for "Comcast <online.communications@alerts.comcast.net>", or ""
+// when it has none.
+func SenderName(msg *gmail.Message) string {
+	from := header(msg, "From")
+	if from == "" {
+		return ""
+	}
+	a, err := mail.ParseAddress(from)
+	if err != nil {
+		return ""
+	}
+	return a.Name
+}
+
+// contentType returns the MIME type of a part, from its Content-Type header
+// when the MIME type isn't set.
+func contentType(p *gmail.MessagePart) string {
+	if p.MimeType != "" {
+		return p.MimeType
+	}
+	for _, h := range p.Headers {
+		if strings.EqualFold(h.Name, "Content-Type") {
+			return strings.ToLower(h.Value)
+		}
+	}
+	return ""
+}
+
+// htmlText returns the text of an HTML document with whitespace collapsed.
+func htmlText(data []byte) string {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return string(data)
+	}
+	return text(doc)
+}
+
+// decodeBase64 tries padded base64url first, then raw (unpadded) base64url.
+func decodeBase64(s string) ([]byte, error) {
+	if b, err := base64.URLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,36 @@
+package email
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestHTML(t *testing.T) {
+	html := "<html><body>Receipt</body></html>"
+	msg := &gmail.Message{
+		Payload: &gmail.MessagePart{
+			MimeType: "multipart/alternative",
+			Parts: []*gmail.MessagePart{
+				{MimeType: "text/plain", Body: &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte("Receipt"))}},
+				{
+					Headers: []*gmail.MessagePartHeader{{Name: "Content-Type", Value: "text/html; charset=UTF-8"}},
+					Body:    &gmail.MessagePartBody{Data: base64.RawURLEncoding.EncodeToString([]byte(html))},
+				},
+			},
+		},
+	}
+	got, err := HTML(msg)
+	if err != nil {
+		t.Fatalf("HTML returned error: %v", err)
+	}
+	if string(got) != html {
+		t.Fatalf("expected %q, got %q", html, got)
+	}
+
+	msg.Payload.Parts = msg.Payload.Parts[:1]
+	if got, err := HTML(msg); err != nil || got != nil {
+		t.Fatalf("expected no HTML part, got %q, %v", got, err)
+	}
+}
@@ -0,0 +1,131 @@
+// Package email holds helpers shared by providers for reading alert emails.
+package email
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Field is the label cell of an HTML table row and the value cell after it.
+type Field struct {
+	Label string
+	Value string
+}
+
+// Fields are the label/value rows of a document, in document order.
+type Fields []Field
+
+// TableFields parses an HTML document and returns the rows of its tables
+// whose first non-empty cell is followed by another one, such as
+// "Account | Chase Freedom (...8719)". Cell text includes nested elements
+// with whitespace collapsed. Cells holding a nested table are layout rather
+// than data and are skipped.
+func TableFields(r io.Reader) (Fields, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	var fields Fields
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Tr {
+			if f, ok := rowField(n); ok {
+				fields = append(fields, f)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return fields, nil
+}
+
+// Get returns the value of the first field labelled with one of labels,
+// ignoring case, spacing and a trailing colon. Labels are tried in order.
+func (fs Fields) Get(labels ...string) string {
+	for _, l := range labels {
+		l = normalizeLabel(l)
+		for _, f := range fs {
+			if normalizeLabel(f.Label) == l {
+				return f.Value
+			}
+		}
+	}
+	return ""
+}
+
+// Find returns the value of the first field whose normalized label, see
+// Get, satisfies match.
+func (fs Fields) Find(match func(label string) bool) (string, bool) {
+	for _, f := range fs {
+		if match(normalizeLabel(f.Label)) {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+func rowField(tr *html.Node) (Field, bool) {
+	var cells []string
+	for c := tr.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || (c.DataAtom != atom.Td && c.DataAtom != atom.Th) {
+			continue
+		}
+		if hasTable(c) {
+			return Field{}, false
+		}
+		if s := text(c); s != "" {
+			cells = append(cells, s)
+		}
+	}
+	if len(cells) < 2 {
+		return Field{}, false
+	}
+	return Field{Label: cells[0], Value: cells[1]}, true
+}
+
+func hasTable(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == atom.Table || hasTable(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// text returns the text below n with runs of whitespace, including
+// non-breaking spaces, collapsed to a single space.
+func text(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+		case n.Type == html.ElementNode && (n.DataAtom == atom.Style || n.DataAtom == atom.Script):
+			return
+		case n.Type == html.ElementNode && n.DataAtom == atom.Br:
+			b.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		// keep the text of neighbouring cells and blocks apart
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Td, atom.Th, atom.Tr, atom.P, atom.Div, atom.Li, atom.H1, atom.H2, atom.H3:
+				b.WriteString(" ")
+			}
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func normalizeLabel(l string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.Join(strings.Fields(l), " "), ":"))
+}
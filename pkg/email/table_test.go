@@ -0,0 +1,47 @@
+package email
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTableFields(t *testing.T) {
+	doc := `<html><body>
+<table><tr><td>
+  <table>
+    <tr><td colspan="3"><h1>Your transaction</h1></td></tr>
+    <tr>
+      <td><span>Account</span></td>
+      <td>&nbsp;</td>
+      <td><b>Chase&nbsp;Freedom</b>
+          <span>(...8719)</span></td>
+    </tr>
+    <tr><th>Amount:</th><td>$4.04</td></tr>
+  </table>
+</td><td><table><tr><td>Layout</td><td>only</td></tr></table></td></tr></table>
+</body></html>`
+	fields, err := TableFields(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("TableFields returned error: %v", err)
+	}
+	want := Fields{
+		{Label: "Account", Value: "Chase Freedom (...8719)"},
+		{Label: "Amount:", Value: "$4.04"},
+		{Label: "Layout", Value: "only"},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Fatalf("TableFields() = %q, want %q", fields, want)
+	}
+
+	if got := fields.Get("Total", "amount"); got != "$4.04" {
+		t.Errorf("Get() = %q, want $4.04", got)
+	}
+	if got := fields.Get("Merchant"); got != "" {
+		t.Errorf("Get() of a missing label = %q", got)
+	}
+	v, ok := fields.Find(func(label string) bool { return strings.HasPrefix(label, "acc") })
+	if !ok || v != "Chase Freedom (...8719)" {
+		t.Errorf("Find() = %q, %v", v, ok)
+	}
+}
@@ -0,0 +1,181 @@
+package ledger
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var amountExp = regexp.MustCompile(`^([+-]?)\s*("[^"]+"|[^\d\s.,+-]*)\s*([+-]?)\s*(\d{1,3}(?:,\d{3})+|\d*)(?:\.(\d+))?\s*("[^"]+"|[^\d\s.,+-]*)$`)
+
+// Amount is a signed quantity of a single commodity, such as $1,000.00.
+// Quantity is stored in units of 10^-Scale so that no precision is lost.
+type Amount struct {
+	Commodity string
+	Quantity  int64
+	Scale     int
+}
+
+// ParseAmount parses amounts as they appear in alert emails, e.g. "$45.67",
+// "-$1,000.00", "$-3.10" or "12.5 EUR", and commodities quoted the way
+// journals write tickers with digits or punctuation, e.g. `2 "BRK.B"`.
+func ParseAmount(s string) (Amount, error) {
+	m := amountExp.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil || (m[4] == "" && m[5] == "") {
+		return Amount{}, fmt.Errorf("invalid amount %q", s)
+	}
+	if m[1] != "" && m[3] != "" {
+		return Amount{}, fmt.Errorf("invalid amount %q", s)
+	}
+	if m[2] != "" && m[6] != "" {
+		return Amount{}, fmt.Errorf("invalid amount %q: more than one commodity", s)
+	}
+	digits := strings.ReplaceAll(m[4], ",", "") + m[5]
+	q, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Amount{}, fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+	if m[1] == "-" || m[3] == "-" {
+		q = -q
+	}
+	return Amount{Commodity: strings.Trim(m[2]+m[6], `"`), Quantity: q, Scale: len(m[5])}, nil
+}
+
+// Neg returns the amount with its sign flipped.
+func (a Amount) Neg() Amount {
+	a.Quantity = -a.Quantity
+	return a
+}
+
+// Abs returns the amount without its sign.
+func (a Amount) Abs() Amount {
+	if a.Quantity < 0 {
+		return a.Neg()
+	}
+	return a
+}
+
+// Add returns a+b. Both amounts must be of the same commodity.
+func (a Amount) Add(b Amount) (Amount, error) {
+	if a.Commodity != b.Commodity {
+		return Amount{}, fmt.Errorf("cannot add %s and %s", a, b)
+	}
+	a, b = a.rescale(b.Scale), b.rescale(a.Scale)
+	a.Quantity += b.Quantity
+	return a, nil
+}
+
+// Sub returns a-b. Both amounts must be of the same commodity.
+func (a Amount) Sub(b Amount) (Amount, error) {
+	return a.Add(b.Neg())
+}
+
+// Cmp compares the quantities of a and b, ignoring commodities, and returns
+// -1, 0 or +1.
+func (a Amount) Cmp(b Amount) int {
+	a, b = a.rescale(b.Scale), b.rescale(a.Scale)
+	switch {
+	case a.Quantity < b.Quantity:
+		return -1
+	case a.Quantity > b.Quantity:
+		return 1
+	}
+	return 0
+}
+
+// rescale increases the scale of a to at least scale.
+func (a Amount) rescale(scale int) Amount {
+	for a.Scale < scale {
+		a.Quantity *= 10
+		a.Scale++
+	}
+	return a
+}
+
+// Round rounds the amount half away from zero to at most scale decimals.
+func (a Amount) Round(scale int) Amount {
+	var r int64
+	for a.Scale > scale {
+		r = a.Quantity % 10
+		a.Quantity /= 10
+		a.Scale--
+	}
+	switch {
+	case r >= 5:
+		a.Quantity++
+	case r <= -5:
+		a.Quantity--
+	}
+	return a
+}
+
+// IsZero reports whether the quantity is zero.
+func (a Amount) IsZero() bool {
+	return a.Quantity == 0
+}
+
+// Number formats the quantity without a commodity, e.g. "-1000.00".
+func (a Amount) Number() string {
+	q := a.Quantity
+	sign := ""
+	if q < 0 {
+		sign = "-"
+		q = -q
+	}
+	s := strconv.FormatInt(q, 10)
+	if a.Scale <= 0 {
+		return sign + s
+	}
+	if len(s) <= a.Scale {
+		s = strings.Repeat("0", a.Scale-len(s)+1) + s
+	}
+	return sign + s[:len(s)-a.Scale] + "." + s[len(s)-a.Scale:]
+}
+
+// String formats the amount the way hledger and ledger expect it: symbols
+// such as "$" are prefixed and commodity codes such as "EUR" are suffixed,
+// quoted when they hold anything but letters, e.g. `2 "BRK.B"`.
+func (a Amount) String() string {
+	n := a.Number()
+	if a.Commodity == "" {
+		return n
+	}
+	if isSymbol(a.Commodity) {
+		if strings.HasPrefix(n, "-") {
+			return "-" + a.Commodity + n[1:]
+		}
+		return a.Commodity + n
+	}
+	if needsQuotes(a.Commodity) {
+		return n + ` "` + a.Commodity + `"`
+	}
+	return n + " " + a.Commodity
+}
+
+// needsQuotes reports whether a commodity code has characters other than
+// letters, which journals only read in double quotes.
+func needsQuotes(c string) bool {
+	for _, r := range c {
+		if !unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSymbol(c string) bool {
+	for _, r := range c {
+		if r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Mul returns a multiplied by b, in b's commodity, the way a unit cost
+// converts an amount.
+func (a Amount) Mul(b Amount) Amount {
+	return Amount{Commodity: b.Commodity, Quantity: a.Quantity * b.Quantity, Scale: a.Scale + b.Scale}
+}
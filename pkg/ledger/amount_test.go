@@ -0,0 +1,57 @@
+package ledger
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   Amount
+		str    string
+		number string
+	}{
+		{in: "$45.67", want: Amount{Commodity: "$", Quantity: 4567, Scale: 2}, str: "$45.67", number: "45.67"},
+		{in: "$1,000.00", want: Amount{Commodity: "$", Quantity: 100000, Scale: 2}, str: "$1000.00", number: "1000.00"},
+		{in: "-$3.10", want: Amount{Commodity: "$", Quantity: -310, Scale: 2}, str: "-$3.10", number: "-3.10"},
+		{in: "$-0.05", want: Amount{Commodity: "$", Quantity: -5, Scale: 2}, str: "-$0.05", number: "-0.05"},
+		{in: "12.5 EUR", want: Amount{Commodity: "EUR", Quantity: 125, Scale: 1}, str: "12.5 EUR", number: "12.5"},
+		{in: "10", want: Amount{Quantity: 10}, str: "10", number: "10"},
+		{in: "0.01234567 BTC", want: Amount{Commodity: "BTC", Quantity: 1234567, Scale: 8}, str: "0.01234567 BTC", number: "0.01234567"},
+		{in: `-2 "BRK.B"`, want: Amount{Commodity: "BRK.B", Quantity: -2}, str: `-2 "BRK.B"`, number: "-2"},
+		{in: `"VT2" 5`, want: Amount{Commodity: "VT2", Quantity: 5}, str: `5 "VT2"`, number: "5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseAmount(tt.in)
+			if err != nil {
+				t.Fatalf("ParseAmount(%q) returned error: %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Fatalf("ParseAmount(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+			if got.String() != tt.str {
+				t.Errorf("String() = %q, want %q", got.String(), tt.str)
+			}
+			if got.Number() != tt.number {
+				t.Errorf("Number() = %q, want %q", got.Number(), tt.number)
+			}
+		})
+	}
+
+	for _, tt := range []struct{ in, want string }{
+		{"$902.4600", "$902.46"},
+		{"$33.1575", "$33.16"},
+		{"-$0.045", "-$0.05"},
+		{"$1.5", "$1.5"},
+	} {
+		a, _ := ParseAmount(tt.in)
+		if got := a.Round(2).String(); got != tt.want {
+			t.Errorf("ParseAmount(%q).Round(2) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "$", "abc", "$1.00 USD", "--1", `1 "BRK.B`} {
+		if _, err := ParseAmount(in); err == nil {
+			t.Errorf("ParseAmount(%q) expected error", in)
+		}
+	}
+}
@@ -1,45 +1,90 @@
 package ledger
 
 import (
-	"fmt"
-	"strings"
+	"maps"
+	"slices"
 	"time"
 )
 
+// Status is the clearing state of a transaction as written in a journal.
+type Status string
+
+const (
+	Unmarked Status = ""
+	// Pending marks authorizations and other charges that haven't settled.
+	// Card transaction alerts are sent at authorization, before the charge
+	// posts, so providers mark them pending.
+	Pending Status = "!"
+	// Cleared marks settled transactions. Refunds, credits and reversals are
+	// only announced once they post, so providers mark them cleared.
+	Cleared Status = "*"
+)
+
+// Name returns "pending", "cleared" or "" for unmarked transactions.
+func (s Status) Name() string {
+	switch s {
+	case Pending:
+		return "pending"
+	case Cleared:
+		return "cleared"
+	}
+	return ""
+}
+
+// Transaction is a header (date, status, code, payee, note and tags) and its
+// postings. Most emails describe a single posting, given by Account, Amount
+// and IsReceive and balanced by Uncategorised; Postings lists them all when
+// the transaction is split.
 type Transaction struct {
-	ID        string
+	ID     string
+	Status Status
+	// Code is written in parentheses before the payee, e.g. a check number.
+	Code      string
 	Payee     string
 	Amount    string
 	Note      string
 	Date      time.Time
 	Account   string
 	IsReceive bool
+	// Ref is the ID of the original transaction a refund, credit or reversal
+	// applies to, when the email mentions it.
+	Ref string
+	// Tags holds extra details parsed from the email, such as the funding
+	// source of a payment.
+	Tags map[string]string
+	// Postings, when set, replace the single posting to Account and list
+	// every posting of the transaction, including the uncategorised side.
+	// Account, Amount and IsReceive then describe the posting to the
+	// provider's account. See Check for how they must balance.
+	Postings []Posting
+	Source   Source
 }
 
-func (t Transaction) Print() string {
-	var b strings.Builder
-	fmt.Fprintf(&b, "\n%s ", t.Date.Format("2006/01/02"))
-	fmt.Fprintf(&b, "%s\n", t.Payee)
-	fmt.Fprintf(&b, "    %s  ", t.Account)
-	if !t.IsReceive {
-		fmt.Fprint(&b, "-")
-	}
-	fmt.Fprintf(&b, "%s\n", t.Amount)
-	if t.ID != "" {
-		fmt.Fprintf(&b, "    ; id: %s\n", t.ID)
-	}
+// Uncategorised is the account balancing a transaction until it is
+// categorised.
+const Uncategorised = "e.FIXME"
+
+// TagNames returns the keys of t.Tags in sorted order.
+func (t Transaction) TagNames() []string {
+	return slices.Sorted(maps.Keys(t.Tags))
+}
 
-	// virtually undo to not mess with the unbudgeted stuff
-	if strings.Index(t.Account, "assets:") == 0 {
-		fmt.Fprintf(&b, "    (%s)  ", t.Account)
-		if t.IsReceive {
-			fmt.Fprint(&b, "-")
-		}
-		fmt.Fprintf(&b, "%s\n", t.Amount)
+// Source records where a transaction was parsed from.
+type Source struct {
+	MessageID string
+	Provider  string
+	Received  time.Time
+}
+
+// SignedAmount parses Amount and applies the direction of the transaction:
+// money leaving Account is negative, money received is positive.
+func (t Transaction) SignedAmount() (Amount, error) {
+	a, err := ParseAmount(t.Amount)
+	if err != nil {
+		return Amount{}, err
 	}
-	if t.Note != "" {
-		fmt.Fprintf(&b, "    ; %s\n", t.Note)
+	if !t.IsReceive {
+		a = a.Neg()
 	}
-	fmt.Fprintf(&b, "    e.FIXME\n")
-	return b.String()
+	return a, nil
 }
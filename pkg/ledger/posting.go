@@ -0,0 +1,189 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Posting is an account and signed amount. An empty Amount is left for the
+// journal to balance. Cost is the price of the amount in another commodity,
+// per unit or, with TotalCost, for the whole amount. Balance asserts the
+// account's balance after the posting and Comment is written after it.
+type Posting struct {
+	Account   string
+	Amount    string
+	Cost      string
+	TotalCost bool
+	Balance   string
+	Comment   string
+	Type      string
+}
+
+// Value returns the amount followed by its cost and balance assertion, e.g.
+// "-25.50 EUR @ $1.0945" or "$0.00 = $1234.56".
+func (p Posting) Value() string {
+	v := p.Amount
+	if p.Cost != "" && p.Amount != "" {
+		if p.TotalCost {
+			v += " @@ " + p.Cost
+		} else {
+			v += " @ " + p.Cost
+		}
+	}
+	if p.Balance != "" {
+		if v != "" {
+			v += " "
+		}
+		v += "= " + p.Balance
+	}
+	return v
+}
+
+// Name returns the account wrapped in the brackets of its posting type.
+func (p Posting) Name() string {
+	switch p.Type {
+	case Virtual:
+		return "(" + p.Account + ")"
+	case BalancedVirtual:
+		return "[" + p.Account + "]"
+	}
+	return p.Account
+}
+
+// Weight returns the amount the posting contributes to the balance of its
+// transaction: the amount itself, or its cost when it has one.
+func (p Posting) Weight() (Amount, error) {
+	a, err := ParseAmount(p.Amount)
+	if err != nil {
+		return Amount{}, err
+	}
+	if p.Cost == "" {
+		return a, nil
+	}
+	c, err := ParseAmount(p.Cost)
+	if err != nil {
+		return Amount{}, err
+	}
+	if p.TotalCost {
+		c = c.Abs()
+		if a.Quantity < 0 {
+			c = c.Neg()
+		}
+		return c, nil
+	}
+	return a.Mul(c), nil
+}
+
+// ErrUnbalanced is returned by Check for postings that don't sum to zero.
+var ErrUnbalanced = errors.New("transaction doesn't balance")
+
+// Check verifies that the real postings of t, and separately its balanced
+// virtual postings, sum to zero in every commodity, allowing for rounding at
+// the precision the amounts are written with. At most one posting of each
+// group may leave its amount to be inferred. A transaction without explicit
+// postings is balanced by Uncategorised and always passes.
+func (t Transaction) Check() error {
+	for _, typ := range []string{Real, BalancedVirtual} {
+		sums, elided, err := residual(t.Postings, typ)
+		if err != nil {
+			return err
+		}
+		if elided >= 0 {
+			continue
+		}
+		for _, c := range slices.Sorted(maps.Keys(sums)) {
+			if !sums[c].negligible() {
+				return fmt.Errorf("%w: off by %s", ErrUnbalanced, sums[c].round())
+			}
+		}
+	}
+	return nil
+}
+
+// AutoBalance sets the amount of the posting left without one, in each
+// group, to what balances the others, the way hledger and ledger infer it.
+func (t *Transaction) AutoBalance() error {
+	for _, typ := range []string{Real, BalancedVirtual} {
+		sums, elided, err := residual(t.Postings, typ)
+		if err != nil {
+			return err
+		}
+		var left []string
+		for _, c := range slices.Sorted(maps.Keys(sums)) {
+			if !sums[c].negligible() {
+				left = append(left, c)
+			}
+		}
+		if elided < 0 {
+			if len(left) > 0 {
+				return fmt.Errorf("%w: off by %s", ErrUnbalanced, sums[left[0]].round())
+			}
+			continue
+		}
+		if len(left) != 1 {
+			return fmt.Errorf("can't infer the amount of the posting to %s from %d commodities", t.Postings[elided].Account, len(left))
+		}
+		t.Postings[elided].Amount = sums[left[0]].round().Neg().String()
+	}
+	return nil
+}
+
+// sum is the running balance of one commodity and the precision it is
+// written with. Amounts written in the commodity set the precision; those
+// converted at a cost only do when there are none, using the cost's.
+type sum struct {
+	Amount
+	precision int
+	direct    bool
+}
+
+// negligible reports whether the sum rounds to zero at its precision.
+func (s sum) negligible() bool {
+	return s.round().IsZero()
+}
+
+// round rounds the sum half away from zero to its precision.
+func (s sum) round() Amount {
+	return s.rescale(s.precision).Round(s.precision)
+}
+
+// residual sums the weights of the postings of type typ by commodity and
+// returns the index of the posting without an amount, or -1.
+func residual(ps []Posting, typ string) (map[string]sum, int, error) {
+	sums := make(map[string]sum)
+	elided := -1
+	for i, p := range ps {
+		if p.Type != typ {
+			continue
+		}
+		if p.Amount == "" {
+			if elided >= 0 {
+				return nil, -1, fmt.Errorf("postings to %s and %s both have no amount", ps[elided].Account, p.Account)
+			}
+			elided = i
+			continue
+		}
+		w, err := p.Weight()
+		if err != nil {
+			return nil, -1, fmt.Errorf("posting to %s: %w", p.Account, err)
+		}
+		s, ok := sums[w.Commodity]
+		if !ok {
+			s.Commodity = w.Commodity
+		}
+		s.Amount, _ = s.Add(w)
+		if p.Cost == "" {
+			if !s.direct {
+				s.precision, s.direct = 0, true
+			}
+			s.precision = max(s.precision, w.Scale)
+		} else if !s.direct {
+			c, _ := ParseAmount(p.Cost)
+			s.precision = max(s.precision, c.Scale)
+		}
+		sums[w.Commodity] = s
+	}
+	return sums, elided, nil
+}
@@ -0,0 +1,134 @@
+package ledger
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPostingValue(t *testing.T) {
+	tests := []struct {
+		p    Posting
+		want string
+	}{
+		{Posting{Account: "a"}, ""},
+		{Posting{Account: "a", Amount: "-25.50 EUR", Cost: "$1.0945"}, "-25.50 EUR @ $1.0945"},
+		{Posting{Account: "a", Amount: "-25.50 EUR", Cost: "$27.91", TotalCost: true}, "-25.50 EUR @@ $27.91"},
+		{Posting{Account: "a", Amount: "$0.00", Balance: "$1234.56"}, "$0.00 = $1234.56"},
+		{Posting{Account: "a", Balance: "$1234.56"}, "= $1234.56"},
+	}
+	for _, tt := range tests {
+		if got := tt.p.Value(); got != tt.want {
+			t.Errorf("Value() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		postings []Posting
+		wantErr  bool
+	}{
+		{name: "single posting"},
+		{
+			name: "split",
+			postings: []Posting{
+				{Account: "liabilities:chase", Amount: "-$50.00"},
+				{Account: "expenses:groceries", Amount: "$42.50"},
+				{Account: "expenses:household", Amount: "$7.50"},
+			},
+		},
+		{
+			name: "elided",
+			postings: []Posting{
+				{Account: "liabilities:chase", Amount: "-$50.00"},
+				{Account: "expenses:groceries"},
+			},
+		},
+		{
+			name: "unit cost rounds",
+			postings: []Posting{
+				{Account: "assets:paypal", Amount: "-$27.91"},
+				{Account: "e.FIXME", Amount: "25.50 EUR", Cost: "$1.0945"},
+			},
+		},
+		{
+			name: "total cost",
+			postings: []Posting{
+				{Account: "assets:paypal", Amount: "-$27.91"},
+				{Account: "e.FIXME", Amount: "25.50 EUR", Cost: "$27.91", TotalCost: true},
+			},
+		},
+		{
+			name: "virtual postings don't count",
+			postings: []Posting{
+				{Account: "assets:checking", Amount: "-$5.00"},
+				{Account: "assets:checking", Amount: "$5.00", Type: Virtual},
+				{Account: "e.FIXME", Amount: "$5.00"},
+			},
+		},
+		{
+			name: "unbalanced",
+			postings: []Posting{
+				{Account: "liabilities:chase", Amount: "-$50.00"},
+				{Account: "expenses:groceries", Amount: "$42.50"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unbalanced virtual",
+			postings: []Posting{
+				{Account: "assets:checking", Amount: "-$5.00"},
+				{Account: "e.FIXME", Amount: "$5.00"},
+				{Account: "budget:food", Amount: "$5.00", Type: BalancedVirtual},
+			},
+			wantErr: true,
+		},
+		{
+			name: "two elided",
+			postings: []Posting{
+				{Account: "liabilities:chase", Amount: "-$50.00"},
+				{Account: "expenses:groceries"},
+				{Account: "expenses:household"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := Transaction{Account: "liabilities:chase", Amount: "$50.00", Postings: tt.postings}
+			err := tx.Check()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	tx := Transaction{Postings: []Posting{{Account: "a", Amount: "$1.00"}, {Account: "b", Amount: "-$0.99"}}}
+	if err := tx.Check(); !errors.Is(err, ErrUnbalanced) || err.Error() != "transaction doesn't balance: off by $0.01" {
+		t.Errorf("Check() error = %v", err)
+	}
+}
+
+func TestAutoBalance(t *testing.T) {
+	tx := Transaction{Postings: []Posting{
+		{Account: "assets:paypal", Amount: "-$26.45"},
+		{Account: "expenses:fees:paypal", Amount: "$1.46"},
+		{Account: "e.FIXME"},
+	}}
+	if err := tx.AutoBalance(); err != nil {
+		t.Fatalf("AutoBalance returned error: %v", err)
+	}
+	if got := tx.Postings[2].Amount; got != "$24.99" {
+		t.Errorf("inferred amount = %q, want $24.99", got)
+	}
+
+	tx = Transaction{Postings: []Posting{
+		{Account: "assets:paypal", Amount: "-$27.91"},
+		{Account: "assets:wise", Amount: "-10.00 EUR"},
+		{Account: "e.FIXME"},
+	}}
+	if err := tx.AutoBalance(); err == nil {
+		t.Errorf("expected an error inferring two commodities")
+	}
+}
@@ -0,0 +1,128 @@
+package ledger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Posting types, see RulePosting.
+const (
+	Real            = ""
+	Virtual         = "virtual"
+	BalancedVirtual = "balanced"
+)
+
+// PostingRule adds postings to transactions whose account starts with
+// Prefix and/or matches Regex.
+type PostingRule struct {
+	Prefix   string        `yaml:"prefix"`
+	Regex    string        `yaml:"regex"`
+	Postings []RulePosting `yaml:"postings"`
+
+	re *regexp.Regexp
+}
+
+// RulePosting describes a posting added by a PostingRule. Its amount is the
+// amount of the matched account, negated when Negate is set.
+type RulePosting struct {
+	// Account defaults to the matched account.
+	Account string `yaml:"account"`
+	Negate  bool   `yaml:"negate"`
+	// Type is empty for a real posting, "virtual" for (account) or
+	// "balanced" for [account].
+	Type string `yaml:"type"`
+}
+
+// BudgetRules is the envelope budgeting convention of the budget layout:
+// asset accounts get a virtual posting undoing the amount so it doesn't
+// count against the unbudgeted funds.
+var BudgetRules = []PostingRule{
+	{Prefix: "assets:", Postings: []RulePosting{{Negate: true, Type: Virtual}}},
+}
+
+// CompileRules validates rules and prepares their regular expressions.
+func CompileRules(rules []PostingRule) error {
+	for i := range rules {
+		r := &rules[i]
+		if r.Prefix == "" && r.Regex == "" {
+			return fmt.Errorf("posting rule %d: needs a prefix or regex", i)
+		}
+		if r.Regex != "" {
+			re, err := regexp.Compile(r.Regex)
+			if err != nil {
+				return fmt.Errorf("posting rule %d: %w", i, err)
+			}
+			r.re = re
+		}
+		for _, p := range r.Postings {
+			switch p.Type {
+			case Real, Virtual, BalancedVirtual:
+			default:
+				return fmt.Errorf("posting rule %d: unknown posting type %q", i, p.Type)
+			}
+		}
+	}
+	return nil
+}
+
+func (r PostingRule) matches(account string) bool {
+	if r.Prefix != "" && !strings.HasPrefix(account, r.Prefix) {
+		return false
+	}
+	if r.Regex != "" {
+		re := r.re
+		if re == nil {
+			re = regexp.MustCompile(r.Regex)
+		}
+		return re.MatchString(account)
+	}
+	return true
+}
+
+// PostingsWith returns t.Postings, or the single posting to t.Account when
+// there are none, with the postings added by rules after each matching
+// posting. The balancing posting of a single posting transaction is not
+// included.
+func (t Transaction) PostingsWith(rules []PostingRule) []Posting {
+	base := t.Postings
+	if len(base) == 0 {
+		amt := t.Amount
+		if !t.IsReceive {
+			amt = negate(amt)
+		}
+		base = []Posting{{Account: t.Account, Amount: amt}}
+	}
+	var ps []Posting
+	for _, bp := range base {
+		ps = append(ps, bp)
+		if bp.Type != Real || bp.Amount == "" {
+			continue
+		}
+		for _, r := range rules {
+			if !r.matches(bp.Account) {
+				continue
+			}
+			for _, rp := range r.Postings {
+				p := Posting{Account: rp.Account, Amount: bp.Amount, Type: rp.Type}
+				if p.Account == "" {
+					p.Account = bp.Account
+				}
+				if rp.Negate {
+					p.Amount = negate(bp.Amount)
+				}
+				ps = append(ps, p)
+			}
+		}
+	}
+	return ps
+}
+
+// negate flips the sign of an amount as written in an email, keeping its
+// formatting.
+func negate(amt string) string {
+	if rest, ok := strings.CutPrefix(amt, "-"); ok {
+		return rest
+	}
+	return "-" + amt
+}
@@ -0,0 +1,70 @@
+package ledger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPostingsWith(t *testing.T) {
+	rules := []PostingRule{
+		{Prefix: "assets:", Postings: []RulePosting{{Negate: true, Type: Virtual}}},
+		{Regex: `:checking$`, Postings: []RulePosting{{Account: "budget:cash", Type: BalancedVirtual}}},
+	}
+	if err := CompileRules(rules); err != nil {
+		t.Fatalf("CompileRules returned error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		tx   Transaction
+		want []Posting
+	}{
+		{
+			name: "no match",
+			tx:   Transaction{Account: "liabilities:discover", Amount: "$1.00"},
+			want: []Posting{{Account: "liabilities:discover", Amount: "-$1.00"}},
+		},
+		{
+			name: "prefix match",
+			tx:   Transaction{Account: "assets:savings", Amount: "$250.00", IsReceive: true},
+			want: []Posting{
+				{Account: "assets:savings", Amount: "$250.00"},
+				{Account: "assets:savings", Amount: "-$250.00", Type: Virtual},
+			},
+		},
+		{
+			name: "both rules",
+			tx:   Transaction{Account: "assets:checking", Amount: "$100.00"},
+			want: []Posting{
+				{Account: "assets:checking", Amount: "-$100.00"},
+				{Account: "assets:checking", Amount: "$100.00", Type: Virtual},
+				{Account: "budget:cash", Amount: "-$100.00", Type: BalancedVirtual},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.tx.PostingsWith(rules)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PostingsWith() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	names := []string{Posting{Account: "a"}.Name(), Posting{Account: "a", Type: Virtual}.Name(), Posting{Account: "a", Type: BalancedVirtual}.Name()}
+	if !reflect.DeepEqual(names, []string{"a", "(a)", "[a]"}) {
+		t.Errorf("unexpected posting names %v", names)
+	}
+}
+
+func TestCompileRulesErrors(t *testing.T) {
+	for _, r := range []PostingRule{
+		{},
+		{Regex: "("},
+		{Prefix: "assets:", Postings: []RulePosting{{Type: "square"}}},
+	} {
+		if err := CompileRules([]PostingRule{r}); err == nil {
+			t.Errorf("expected error for %+v", r)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+)
+
+// csvHeader names the columns of CSVWriter. Columns are only ever added at
+// the end, so that files written by earlier versions can be appended to.
+var csvHeader = []string{
+	"date", "payee", "account", "amount", "commodity", "raw_amount",
+	"is_receive", "id", "note", "message_id", "provider", "received",
+	"status", "ref", "tags", "postings",
+}
+
+// CSVWriter writes transactions as CSV rows preceded by a header row.
+type CSVWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func NewCSV(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+func (cw *CSVWriter) Write(t ledger.Transaction) error {
+	r, err := newRecord(t)
+	if err != nil {
+		return err
+	}
+	if !cw.header {
+		if err := cw.w.Write(csvHeader); err != nil {
+			return err
+		}
+		cw.header = true
+	}
+	err = cw.w.Write([]string{
+		r.Date, r.Payee, r.Account, r.Amount, r.Commodity, r.RawAmount,
+		strconv.FormatBool(r.IsReceive), r.ID, r.Note, r.MessageID, r.Provider, r.Received,
+		r.Status, r.Ref, tags(t), postings(r),
+	})
+	if err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// tags joins the provider tags of t as "name:value" pairs.
+func tags(t ledger.Transaction) string {
+	var ts []string
+	for _, k := range t.TagNames() {
+		ts = append(ts, k+":"+t.Tags[k])
+	}
+	return strings.Join(ts, ", ")
+}
+
+// postings joins every posting of a split transaction as "account amount",
+// empty when the transaction has a single posting to its account.
+func postings(r record) string {
+	var ps []string
+	for _, p := range r.Postings {
+		ps = append(ps, strings.TrimSpace(p.Account+" "+p.Amount))
+	}
+	return strings.Join(ps, "; ")
+}
+
+func (cw *CSVWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
@@ -0,0 +1,91 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+)
+
+// hledgerCSVHeader names the columns of HledgerCSVWriter. As in csvHeader,
+// columns are only added at the end.
+var hledgerCSVHeader = []string{"date", "description", "amount", "account1", "code", "comment", "status"}
+
+// HledgerCSVWriter writes transactions as CSV meant to be read by
+// `hledger import` together with the rules from HledgerRules. The Gmail
+// message ID is used as the transaction code unless the transaction has one.
+type HledgerCSVWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func NewHledgerCSV(w io.Writer) *HledgerCSVWriter {
+	return &HledgerCSVWriter{w: csv.NewWriter(w)}
+}
+
+func (hw *HledgerCSVWriter) Write(t ledger.Transaction) error {
+	// a row only has room for the posting to the account, balanced by
+	// account2 of the rules
+	if len(t.Postings) > 0 {
+		return fmt.Errorf("hledger-csv can't write the %d postings of %s %s; use the journal or jsonl format", len(t.Postings), t.Date.Format("2006-01-02"), t.Payee)
+	}
+	a, err := t.SignedAmount()
+	if err != nil {
+		return err
+	}
+	if !hw.header {
+		if err := hw.w.Write(hledgerCSVHeader); err != nil {
+			return err
+		}
+		hw.header = true
+	}
+	code := t.Code
+	if code == "" {
+		code = t.Source.MessageID
+	}
+	var comment []string
+	if t.Note != "" {
+		comment = append(comment, t.Note)
+	}
+	if t.ID != "" {
+		comment = append(comment, "id:"+t.ID)
+	}
+	if t.Ref != "" {
+		comment = append(comment, "ref:"+t.Ref)
+	}
+	if ts := tags(t); ts != "" {
+		comment = append(comment, ts)
+	}
+	if t.Source.Provider != "" {
+		comment = append(comment, "provider:"+t.Source.Provider)
+	}
+	err = hw.w.Write([]string{
+		t.Date.Format("2006-01-02"), t.Payee, a.String(), t.Account, code, strings.Join(comment, ", "), string(t.Status),
+	})
+	if err != nil {
+		return err
+	}
+	hw.w.Flush()
+	return hw.w.Error()
+}
+
+func (hw *HledgerCSVWriter) Close() error {
+	hw.w.Flush()
+	return hw.w.Error()
+}
+
+// HledgerRules returns the hledger CSV rules describing the columns written
+// by HledgerCSVWriter. Unmatched transactions are balanced against
+// defaultAccount; categorisation is meant to be added to the generated file
+// with hledger `if` blocks.
+func HledgerRules(defaultAccount string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# generated by emailimport; add `if` blocks below to categorise transactions\n")
+	fmt.Fprintf(&b, "skip 1\n")
+	fmt.Fprintf(&b, "fields %s\n", strings.Join(hledgerCSVHeader, ", "))
+	fmt.Fprintf(&b, "date-format %%Y-%%m-%%d\n")
+	fmt.Fprintf(&b, "account2 %s\n", defaultAccount)
+	return b.String()
+}
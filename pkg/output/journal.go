@@ -0,0 +1,225 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+)
+
+// JournalConfig controls how plain text journal entries are laid out.
+type JournalConfig struct {
+	// Preset selects a built-in layout: budget (default), hledger or ledger.
+	Preset string `yaml:"preset"`
+	// Template replaces the preset's text/template, see journalEntry for the
+	// fields available to it.
+	Template string `yaml:"template"`
+	// DateFormat is a Go time layout overriding the preset's date format.
+	DateFormat string `yaml:"dateFormat"`
+	// Indent is the number of spaces before each posting, 4 by default.
+	Indent int `yaml:"indent"`
+	// AmountColumn right-aligns amounts so they end at this column. Accounts
+	// and amounts are always separated by at least two spaces; zero uses
+	// exactly two.
+	AmountColumn int `yaml:"amountColumn"`
+	// Tags lists the metadata written with each entry: id, ref (the refunded
+	// transaction), source (the Gmail message ID), provider and received.
+	// Defaults to id and ref. Tags parsed by the provider are always written.
+	Tags []string `yaml:"tags"`
+	// PostingRules add postings to matching accounts. When unset the preset's
+	// rules apply; an empty list disables them.
+	PostingRules []ledger.PostingRule `yaml:"postingRules"`
+}
+
+type preset struct {
+	dateFormat string
+	rules      []ledger.PostingRule
+	template   string
+}
+
+var presets = map[string]preset{
+	// budget is the original layout, see ledger.BudgetRules.
+	"budget": {
+		dateFormat: "2006/01/02",
+		rules:      ledger.BudgetRules,
+		template: `{{.Date}}{{with .Status}} {{.}}{{end}}{{with .Code}} ({{.}}){{end}} {{.Payee}}
+{{posting .First}}
+{{range .Tags}}{{indent}}; {{.Name}}: {{.Value}}
+{{end}}{{range .Extra}}{{posting .}}
+{{end}}{{with .Note}}{{indent}}; {{.}}
+{{end}}{{with .CounterAccount}}{{indent}}{{.}}
+{{end}}`,
+	},
+	"hledger": {
+		dateFormat: "2006-01-02",
+		template: `{{.Date}}{{with .Status}} {{.}}{{end}}{{with .Code}} ({{.}}){{end}} {{.Payee}}{{with .Tags}}  ; {{range $i, $t := .}}{{if $i}}, {{end}}{{$t.Name}}:{{tagValue $t.Value}}{{end}}{{end}}
+{{with .Note}}{{indent}}; {{.}}
+{{end}}{{posting .First}}
+{{range .Extra}}{{posting .}}
+{{end}}{{with .CounterAccount}}{{indent}}{{.}}
+{{end}}`,
+	},
+	"ledger": {
+		dateFormat: "2006/01/02",
+		template: `{{.Date}}{{with .Status}} {{.}}{{end}}{{with .Code}} ({{.}}){{end}} {{.Payee}}
+{{range .Tags}}{{indent}}; {{.Name}}: {{.Value}}
+{{end}}{{with .Note}}{{indent}}; {{.}}
+{{end}}{{posting .First}}
+{{range .Extra}}{{posting .}}
+{{end}}{{with .CounterAccount}}{{indent}}{{.}}
+{{end}}`,
+	},
+}
+
+// Tag is a name/value pair attached to a journal entry.
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// journalEntry is the data a journal template is executed with.
+type journalEntry struct {
+	ledger.Transaction
+	// Date is formatted with the configured date format.
+	Date string
+	// First is the posting to the provider's account.
+	First ledger.Posting
+	// Extra holds the remaining postings, including those added by posting
+	// rules.
+	Extra []ledger.Posting
+	// CounterAccount balances the entry until it is categorised. It is empty
+	// when the transaction lists all of its postings.
+	CounterAccount string
+	Tags           []Tag
+}
+
+// JournalWriter renders transactions through a text/template.
+type JournalWriter struct {
+	w    io.Writer
+	conf JournalConfig
+	tmpl *template.Template
+}
+
+func NewJournal(w io.Writer, conf JournalConfig) (*JournalWriter, error) {
+	if conf.Preset == "" {
+		conf.Preset = "budget"
+	}
+	p, ok := presets[conf.Preset]
+	if !ok {
+		return nil, fmt.Errorf("unknown journal preset %q", conf.Preset)
+	}
+	if conf.Template == "" {
+		conf.Template = p.template
+	}
+	if conf.DateFormat == "" {
+		conf.DateFormat = p.dateFormat
+	}
+	if conf.Indent == 0 {
+		conf.Indent = 4
+	}
+	if conf.Tags == nil {
+		conf.Tags = []string{"id", "ref"}
+	}
+	if conf.PostingRules == nil {
+		conf.PostingRules = slices.Clone(p.rules)
+	}
+	if err := ledger.CompileRules(conf.PostingRules); err != nil {
+		return nil, err
+	}
+	for _, name := range conf.Tags {
+		switch name {
+		case "id", "ref", "source", "provider", "received":
+		default:
+			return nil, fmt.Errorf("unknown journal tag %q", name)
+		}
+	}
+
+	jw := &JournalWriter{w: w, conf: conf}
+	tmpl, err := template.New(conf.Preset).Funcs(template.FuncMap{
+		"indent":   jw.indent,
+		"posting":  jw.posting,
+		"tagValue": tagValue,
+	}).Parse(conf.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid journal template: %w", err)
+	}
+	jw.tmpl = tmpl
+	return jw, nil
+}
+
+func (jw *JournalWriter) Write(t ledger.Transaction) error {
+	ps := t.PostingsWith(jw.conf.PostingRules)
+	e := journalEntry{
+		Transaction: t,
+		Date:        t.Date.Format(jw.conf.DateFormat),
+		First:       ps[0],
+		Extra:       ps[1:],
+	}
+	if len(t.Postings) == 0 {
+		e.CounterAccount = ledger.Uncategorised
+	}
+	for _, name := range jw.conf.Tags {
+		var v string
+		switch name {
+		case "id":
+			v = t.ID
+		case "ref":
+			v = t.Ref
+		case "source":
+			v = t.Source.MessageID
+		case "provider":
+			v = t.Source.Provider
+		case "received":
+			if !t.Source.Received.IsZero() {
+				v = t.Source.Received.Format(time.RFC3339)
+			}
+		}
+		if v != "" {
+			e.Tags = append(e.Tags, Tag{Name: name, Value: v})
+		}
+	}
+	for _, k := range t.TagNames() {
+		e.Tags = append(e.Tags, Tag{Name: k, Value: t.Tags[k]})
+	}
+	// entries are separated by a blank line
+	if _, err := io.WriteString(jw.w, "\n"); err != nil {
+		return err
+	}
+	return jw.tmpl.Execute(jw.w, e)
+}
+
+func (jw *JournalWriter) Close() error {
+	return nil
+}
+
+func (jw *JournalWriter) indent() string {
+	return strings.Repeat(" ", jw.conf.Indent)
+}
+
+// tagValue drops the commas from an inline hledger tag value, where a comma
+// ends the tag and would start a bogus one.
+func tagValue(v string) string {
+	return strings.ReplaceAll(v, ",", "")
+}
+
+// posting lays out a posting, right-aligning its amount at the configured
+// column and following it with its comment.
+func (jw *JournalWriter) posting(p ledger.Posting) string {
+	account, amount := p.Name(), p.Value()
+	s := jw.indent() + account
+	if amount != "" {
+		pad := 2
+		if jw.conf.AmountColumn > 0 {
+			pad = max(jw.conf.AmountColumn-jw.conf.Indent-len([]rune(account))-len([]rune(amount)), 2)
+		}
+		s += strings.Repeat(" ", pad) + amount
+	}
+	if p.Comment != "" {
+		s += "  ; " + p.Comment
+	}
+	return s
+}
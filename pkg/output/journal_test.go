@@ -0,0 +1,388 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+)
+
+func TestJournal(t *testing.T) {
+	withdrawal := ledger.Transaction{
+		Date:    time.Date(2023, 5, 17, 0, 0, 0, 0, time.UTC),
+		Payee:   "ATM Withdrawal",
+		Account: "assets:checking",
+		Amount:  "$100.00",
+		ID:      "tx123",
+		Note:    "cash for the market",
+		Source:  ledger.Source{MessageID: "18f2a", Provider: "chase"},
+	}
+
+	tests := []struct {
+		name string
+		conf JournalConfig
+		tx   ledger.Transaction
+		want string
+	}{
+		{
+			name: "budget",
+			tx:   withdrawal,
+			want: `
+2023/05/17 ATM Withdrawal
+    assets:checking  -$100.00
+    ; id: tx123
+    (assets:checking)  $100.00
+    ; cash for the market
+    e.FIXME
+`,
+		},
+		{
+			name: "budget with tags",
+			conf: JournalConfig{Tags: []string{"id", "provider", "received"}},
+			tx:   withdrawal,
+			want: `
+2023/05/17 ATM Withdrawal
+    assets:checking  -$100.00
+    ; id: tx123
+    ; provider: chase
+    (assets:checking)  $100.00
+    ; cash for the market
+    e.FIXME
+`,
+		},
+		{
+			name: "hledger",
+			conf: JournalConfig{Preset: "hledger", Tags: []string{"id", "source", "provider"}, AmountColumn: 40},
+			tx:   withdrawal,
+			want: `
+2023-05-17 ATM Withdrawal  ; id:tx123, source:18f2a, provider:chase
+    ; cash for the market
+    assets:checking             -$100.00
+    e.FIXME
+`,
+		},
+		{
+			name: "hledger tag value with a comma",
+			conf: JournalConfig{Preset: "hledger", Tags: []string{"id"}},
+			tx: ledger.Transaction{
+				Date: time.Date(2023, 5, 17, 0, 0, 0, 0, time.UTC), Payee: "Jane Doe", Account: "assets:paypal", Amount: "$1,020.00",
+				ID: "tx123", Tags: map[string]string{"fee": "$1,020.00", "funding": "Visa x-1234, Checking x-5678"},
+			},
+			want: `
+2023-05-17 Jane Doe  ; id:tx123, fee:$1020.00, funding:Visa x-1234 Checking x-5678
+    assets:paypal  -$1,020.00
+    e.FIXME
+`,
+		},
+		{
+			name: "budget without posting rules",
+			conf: JournalConfig{PostingRules: []ledger.PostingRule{}},
+			tx:   withdrawal,
+			want: `
+2023/05/17 ATM Withdrawal
+    assets:checking  -$100.00
+    ; id: tx123
+    ; cash for the market
+    e.FIXME
+`,
+		},
+		{
+			name: "hledger with balanced virtual rule",
+			conf: JournalConfig{
+				Preset: "hledger",
+				Tags:   []string{},
+				PostingRules: []ledger.PostingRule{{
+					Regex: `^liabilities:(capitalone|chase)$`,
+					Postings: []ledger.RulePosting{
+						{Account: "budget:credit", Negate: true, Type: ledger.BalancedVirtual},
+						{Account: "budget:available", Type: ledger.BalancedVirtual},
+					},
+				}},
+			},
+			tx: sample,
+			want: `
+2025-04-15 ! Large Box Store #5
+    ; tv, mount
+    liabilities:capitalone  -$3,096.00
+    [budget:credit]  $3,096.00
+    [budget:available]  -$3,096.00
+    e.FIXME
+`,
+		},
+		{
+			name: "ledger",
+			conf: JournalConfig{Preset: "ledger", Indent: 2, DateFormat: "2006-01-02"},
+			tx:   sample,
+			want: `
+2025-04-15 ! Large Box Store #5
+  ; id: tx123
+  ; tv, mount
+  liabilities:capitalone  -$3,096.00
+  e.FIXME
+`,
+		},
+		{
+			name: "explicit postings with fee and cost",
+			conf: JournalConfig{Preset: "hledger", Tags: []string{"id"}},
+			tx: ledger.Transaction{
+				Date:      time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+				Status:    ledger.Cleared,
+				Payee:     "Hans Müller",
+				Account:   "assets:paypal",
+				Amount:    "100.00 EUR",
+				IsReceive: true,
+				ID:        "5CD67890IJ",
+				Postings: []ledger.Posting{
+					{Account: "assets:paypal"},
+					{Account: "expenses:fees:paypal", Amount: "$4.20"},
+					{Account: "e.FIXME", Amount: "-100.00 EUR", Cost: "$1.0945"},
+				},
+			},
+			want: `
+2025-09-01 * Hans Müller  ; id:5CD67890IJ
+    assets:paypal
+    expenses:fees:paypal  $4.20
+    e.FIXME  -100.00 EUR @ $1.0945
+`,
+		},
+		{
+			name: "split with code, comments and balance assertion",
+			conf: JournalConfig{Preset: "ledger", Tags: []string{}},
+			tx: ledger.Transaction{
+				Date:    time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC),
+				Status:  ledger.Cleared,
+				Code:    "1042",
+				Payee:   "Corner Market",
+				Account: "assets:checking",
+				Amount:  "$60.00",
+				Postings: []ledger.Posting{
+					{Account: "assets:checking", Amount: "-$60.00", Balance: "$1,940.00"},
+					{Account: "expenses:groceries", Amount: "$40.00"},
+					{Account: "assets:cash", Amount: "$20.00", Comment: "cash back"},
+				},
+			},
+			want: `
+2025/09/02 * (1042) Corner Market
+    assets:checking  -$60.00 = $1,940.00
+    expenses:groceries  $40.00
+    assets:cash  $20.00  ; cash back
+`,
+		},
+		{
+			name: "custom template",
+			conf: JournalConfig{Template: "{{.Date}} {{.Payee}} {{.Transaction.Amount}}\n"},
+			tx:   sample,
+			want: "\n2025/04/15 Large Box Store #5 $3,096.00\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			w, err := NewJournal(&b, tt.conf)
+			if err != nil {
+				t.Fatalf("NewJournal returned error: %v", err)
+			}
+			if err := w.Write(tt.tx); err != nil {
+				t.Fatalf("Write returned error: %v", err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestJournalInvalidConfig(t *testing.T) {
+	for _, conf := range []JournalConfig{
+		{Preset: "beancount"},
+		{Tags: []string{"color"}},
+		{Template: "{{.Date"},
+		{PostingRules: []ledger.PostingRule{{Regex: "("}}},
+		{PostingRules: []ledger.PostingRule{{Prefix: "assets:", Postings: []ledger.RulePosting{{Type: "square"}}}}},
+	} {
+		if _, err := NewJournal(&strings.Builder{}, conf); err == nil {
+			t.Errorf("expected error for %+v", conf)
+		}
+	}
+}
+
+// TestJournalBudget covers the default layout entry by entry.
+func TestJournalBudget(t *testing.T) {
+	tests := []struct {
+		name string
+		tx   ledger.Transaction
+		want string
+	}{
+		{
+			name: "basic transaction",
+			tx: ledger.Transaction{
+				Date:      time.Date(2023, 5, 15, 0, 0, 0, 0, time.UTC),
+				Payee:     "Grocery Store",
+				Account:   "expenses:food",
+				Amount:    "$45.67",
+				IsReceive: true,
+			},
+			want: `
+2023/05/15 Grocery Store
+    expenses:food  $45.67
+    e.FIXME
+`,
+		},
+		{
+			name: "transaction with ID",
+			tx: ledger.Transaction{
+				Date:      time.Date(2023, 5, 16, 0, 0, 0, 0, time.UTC),
+				Payee:     "Salary",
+				Account:   "income:salary",
+				Amount:    "$1000.00",
+				IsReceive: false,
+				ID:        "tx123",
+			},
+			want: `
+2023/05/16 Salary
+    income:salary  -$1000.00
+    ; id: tx123
+    e.FIXME
+`,
+		},
+		{
+			name: "transaction with comma",
+			tx: ledger.Transaction{
+				Date:      time.Date(2023, 5, 16, 0, 0, 0, 0, time.UTC),
+				Payee:     "Salary",
+				Account:   "income:salary",
+				Amount:    "$1,000.00",
+				IsReceive: false,
+				ID:        "tx123",
+			},
+			want: `
+2023/05/16 Salary
+    income:salary  -$1,000.00
+    ; id: tx123
+    e.FIXME
+`,
+		},
+		{
+			name: "asset transaction with virtual undo",
+			tx: ledger.Transaction{
+				Date:      time.Date(2023, 5, 17, 0, 0, 0, 0, time.UTC),
+				Payee:     "ATM Withdrawal",
+				Account:   "assets:checking",
+				Amount:    "$100.00",
+				IsReceive: false,
+			},
+			want: `
+2023/05/17 ATM Withdrawal
+    assets:checking  -$100.00
+    (assets:checking)  $100.00
+    e.FIXME
+`,
+		},
+		{
+			name: "pending transaction",
+			tx: ledger.Transaction{
+				Date:    time.Date(2023, 5, 17, 0, 0, 0, 0, time.UTC),
+				Status:  ledger.Pending,
+				Payee:   "HOLIDAY STATIONS 3826",
+				Account: "liabilities:discover",
+				Amount:  "$1.00",
+			},
+			want: `
+2023/05/17 ! HOLIDAY STATIONS 3826
+    liabilities:discover  -$1.00
+    e.FIXME
+`,
+		},
+		{
+			name: "cleared transaction",
+			tx: ledger.Transaction{
+				Date:      time.Date(2023, 5, 17, 0, 0, 0, 0, time.UTC),
+				Status:    ledger.Cleared,
+				Payee:     "Jane Doe",
+				Account:   "assets:paypal",
+				Amount:    "$20.00",
+				IsReceive: true,
+			},
+			want: `
+2023/05/17 * Jane Doe
+    assets:paypal  $20.00
+    (assets:paypal)  -$20.00
+    e.FIXME
+`,
+		},
+		{
+			name: "refund with reference",
+			tx: ledger.Transaction{
+				Date:      time.Date(2023, 5, 18, 0, 0, 0, 0, time.UTC),
+				Payee:     "Book Store",
+				Account:   "liabilities:paypal",
+				Amount:    "$12.00",
+				IsReceive: true,
+				ID:        "9RF12",
+				Ref:       "4PX98",
+			},
+			want: `
+2023/05/18 Book Store
+    liabilities:paypal  $12.00
+    ; id: 9RF12
+    ; ref: 4PX98
+    e.FIXME
+`,
+		},
+		{
+			name: "transaction with note",
+			tx: ledger.Transaction{
+				Date:      time.Date(2023, 5, 18, 0, 0, 0, 0, time.UTC),
+				Payee:     "Coffee Shop",
+				Account:   "expenses:dining",
+				Amount:    "$4.50",
+				IsReceive: true,
+				Note:      "Business meeting",
+			},
+			want: `
+2023/05/18 Coffee Shop
+    expenses:dining  $4.50
+    ; Business meeting
+    e.FIXME
+`,
+		},
+		{
+			name: "comprehensive transaction",
+			tx: ledger.Transaction{
+				Date:      time.Date(2023, 5, 19, 0, 0, 0, 0, time.UTC),
+				Payee:     "Refund",
+				Account:   "assets:savings",
+				Amount:    "$250.00",
+				IsReceive: true,
+				ID:        "refund123",
+				Note:      "Store credit refund",
+			},
+			want: `
+2023/05/19 Refund
+    assets:savings  $250.00
+    ; id: refund123
+    (assets:savings)  -$250.00
+    ; Store credit refund
+    e.FIXME
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			w, err := NewJournal(&b, JournalConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Write(tt.tx); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("Got:\n%s\nWant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
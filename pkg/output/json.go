@@ -0,0 +1,29 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+)
+
+// JSONLWriter writes one JSON object per transaction per line.
+type JSONLWriter struct {
+	enc *json.Encoder
+}
+
+func NewJSONL(w io.Writer) *JSONLWriter {
+	return &JSONLWriter{enc: json.NewEncoder(w)}
+}
+
+func (jw *JSONLWriter) Write(t ledger.Transaction) error {
+	r, err := newRecord(t)
+	if err != nil {
+		return err
+	}
+	return jw.enc.Encode(r)
+}
+
+func (jw *JSONLWriter) Close() error {
+	return nil
+}
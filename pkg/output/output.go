@@ -0,0 +1,215 @@
+package output
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+)
+
+// Writer emits parsed transactions in one output format. Every Write is
+// flushed to the underlying writer so nothing is lost if the run aborts after
+// a message has been marked as processed.
+type Writer interface {
+	Write(t ledger.Transaction) error
+	Close() error
+}
+
+// Options configures the writers.
+type Options struct {
+	Journal JournalConfig
+}
+
+// New returns the Writer for format. An empty format selects the plain text
+// journal.
+func New(format string, w io.Writer, opts Options) (Writer, error) {
+	switch format {
+	case "", "journal":
+		return NewJournal(w, opts.Journal)
+	case "jsonl":
+		return NewJSONL(w), nil
+	case "csv":
+		return NewCSV(w), nil
+	case "hledger-csv":
+		return NewHledgerCSV(w), nil
+	}
+	return nil, fmt.Errorf("unknown output format %q", format)
+}
+
+// Open returns the Writer for format writing to path, or to stdout when path
+// is empty. Files are appended to so repeated runs accumulate in one file and
+// the CSV header is only written to an empty file; appending under another
+// header is refused. The hledger-csv format requires a path and creates
+// path.rules next to it unless it already exists, leaving any categorisation
+// rules added to it untouched, but refusing rules that name other fields.
+func Open(format, path string, opts Options) (Writer, error) {
+	if path == "" {
+		if format == "hledger-csv" {
+			return nil, errors.New("hledger-csv output needs a file path")
+		}
+		return New(format, os.Stdout, opts)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	w, err := New(format, f, opts)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if fi.Size() > 0 {
+		var header []string
+		switch cw := w.(type) {
+		case *CSVWriter:
+			cw.header, header = true, csvHeader
+		case *HledgerCSVWriter:
+			cw.header, header = true, hledgerCSVHeader
+		}
+		if err := checkHeader(path, header); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	if format == "hledger-csv" {
+		rf, err := os.OpenFile(path+".rules", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			_, err = io.WriteString(rf, HledgerRules(ledger.Uncategorised))
+			if cerr := rf.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if errors.Is(err, fs.ErrExist) {
+			err = checkRules(path+".rules", hledgerCSVHeader)
+		}
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return &fileWriter{Writer: w, f: f}, nil
+}
+
+// checkHeader returns an error when the CSV file at path doesn't start with
+// header, so that rows aren't appended under columns they don't match.
+func checkHeader(path string, header []string) error {
+	if header == nil {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	got, err := csv.NewReader(f).Read()
+	if err != nil {
+		return fmt.Errorf("reading header of %s: %w", path, err)
+	}
+	if !slices.Equal(got, header) {
+		return fmt.Errorf("%s has columns %s, not %s; write to a new file", path, strings.Join(got, ","), strings.Join(header, ","))
+	}
+	return nil
+}
+
+// checkRules returns an error when the hledger rules at path name other
+// fields than header.
+func checkRules(path string, header []string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	want := strings.Join(header, ", ")
+	for _, l := range strings.Split(string(b), "\n") {
+		if fields, ok := strings.CutPrefix(strings.TrimSpace(l), "fields "); ok && strings.TrimSpace(fields) != want {
+			return fmt.Errorf("%s has fields %s, not %s; update its fields line", path, fields, want)
+		}
+	}
+	return nil
+}
+
+type fileWriter struct {
+	Writer
+	f *os.File
+}
+
+func (fw *fileWriter) Close() error {
+	err := fw.Writer.Close()
+	if cerr := fw.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// record is the flattened form of a transaction shared by the JSON and CSV
+// writers.
+type record struct {
+	Date      string            `json:"date"`
+	Status    string            `json:"status,omitempty"`
+	Code      string            `json:"code,omitempty"`
+	Payee     string            `json:"payee"`
+	Account   string            `json:"account"`
+	Amount    string            `json:"amount"`
+	Commodity string            `json:"commodity"`
+	RawAmount string            `json:"raw_amount"`
+	IsReceive bool              `json:"is_receive"`
+	ID        string            `json:"id,omitempty"`
+	Ref       string            `json:"ref,omitempty"`
+	Note      string            `json:"note,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	Postings  []posting         `json:"postings,omitempty"`
+	MessageID string            `json:"message_id,omitempty"`
+	Provider  string            `json:"provider,omitempty"`
+	Received  string            `json:"received,omitempty"`
+}
+
+func newRecord(t ledger.Transaction) (record, error) {
+	a, err := t.SignedAmount()
+	if err != nil {
+		return record{}, err
+	}
+	r := record{
+		Date:      t.Date.Format("2006-01-02"),
+		Status:    t.Status.Name(),
+		Code:      t.Code,
+		Payee:     t.Payee,
+		Account:   t.Account,
+		Amount:    a.Number(),
+		Commodity: a.Commodity,
+		RawAmount: t.Amount,
+		IsReceive: t.IsReceive,
+		ID:        t.ID,
+		Ref:       t.Ref,
+		Note:      t.Note,
+		Tags:      t.Tags,
+		MessageID: t.Source.MessageID,
+		Provider:  t.Source.Provider,
+	}
+	if !t.Source.Received.IsZero() {
+		r.Received = t.Source.Received.Format(time.RFC3339)
+	}
+	for _, p := range t.Postings {
+		r.Postings = append(r.Postings, posting{Account: p.Name(), Amount: p.Value(), Comment: p.Comment})
+	}
+	return r, nil
+}
+
+// posting is a transaction posting in the JSON output. The amount includes
+// its cost and balance assertion, e.g. "-25.50 EUR @ $1.0945", and is empty
+// when it balances the other postings.
+type posting struct {
+	Account string `json:"account"`
+	Amount  string `json:"amount"`
+	Comment string `json:"comment,omitempty"`
+}
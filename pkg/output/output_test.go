@@ -0,0 +1,156 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+)
+
+var sample = ledger.Transaction{
+	ID:      "tx123",
+	Status:  ledger.Pending,
+	Payee:   "Large Box Store #5",
+	Amount:  "$3,096.00",
+	Note:    "tv, mount",
+	Date:    time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC),
+	Account: "liabilities:capitalone",
+	Source: ledger.Source{
+		MessageID: "18f2a",
+		Provider:  "capitalone",
+		Received:  time.Date(2025, 4, 15, 18, 4, 5, 0, time.UTC),
+	},
+}
+
+func TestJSONL(t *testing.T) {
+	var b strings.Builder
+	w, err := New("jsonl", &b, Options{})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if err := w.Write(sample); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	want := `{"date":"2025-04-15","status":"pending","payee":"Large Box Store #5","account":"liabilities:capitalone","amount":"-3096.00","commodity":"$","raw_amount":"$3,096.00","is_receive":false,"id":"tx123","note":"tv, mount","message_id":"18f2a","provider":"capitalone","received":"2025-04-15T18:04:05Z"}
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	var b strings.Builder
+	w, err := New("csv", &b, Options{})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	received := sample
+	received.IsReceive = true
+	received.Status = ledger.Cleared
+	received.Source = ledger.Source{}
+	received.Tags = map[string]string{"funding": "Visa x-1234", "fee": "$0.30"}
+	split := received
+	split.Tags = nil
+	split.Postings = []ledger.Posting{
+		{Account: "liabilities:capitalone", Amount: "$3,096.00"},
+		{Account: ledger.Uncategorised, Amount: "-$3,000.00"},
+		{Account: "expenses:fees", Amount: "-$96.00"},
+	}
+	for _, tx := range []ledger.Transaction{sample, received, split} {
+		if err := w.Write(tx); err != nil {
+			t.Fatalf("Write returned error: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	want := `date,payee,account,amount,commodity,raw_amount,is_receive,id,note,message_id,provider,received,status,ref,tags,postings
+2025-04-15,Large Box Store #5,liabilities:capitalone,-3096.00,$,"$3,096.00",false,tx123,"tv, mount",18f2a,capitalone,2025-04-15T18:04:05Z,pending,,,
+2025-04-15,Large Box Store #5,liabilities:capitalone,3096.00,$,"$3,096.00",true,tx123,"tv, mount",,,,cleared,,"fee:$0.30, funding:Visa x-1234",
+2025-04-15,Large Box Store #5,liabilities:capitalone,3096.00,$,"$3,096.00",true,tx123,"tv, mount",,,,cleared,,,"liabilities:capitalone $3,096.00; e.FIXME -$3,000.00; expenses:fees -$96.00"
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	if _, err := New("xml", &strings.Builder{}, Options{}); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+}
+
+func TestHledgerCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.csv")
+	for i := 0; i < 2; i++ {
+		w, err := Open("hledger-csv", path, Options{})
+		if err != nil {
+			t.Fatalf("Open returned error: %v", err)
+		}
+		if err := w.Write(sample); err != nil {
+			t.Fatalf("Write returned error: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close returned error: %v", err)
+		}
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read output: %v", err)
+	}
+	row := `2025-04-15,Large Box Store #5,-$3096.00,liabilities:capitalone,18f2a,"tv, mount, id:tx123, provider:capitalone",!` + "\n"
+	want := "date,description,amount,account1,code,comment,status\n" + row + row
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	rules, err := os.ReadFile(path + ".rules")
+	if err != nil {
+		t.Fatalf("could not read rules: %v", err)
+	}
+	if string(rules) != HledgerRules("e.FIXME") {
+		t.Errorf("unexpected rules:\n%s", rules)
+	}
+	if !strings.Contains(string(rules), "fields date, description, amount, account1, code, comment, status\n") {
+		t.Errorf("rules missing fields list:\n%s", rules)
+	}
+
+	if _, err := Open("hledger-csv", "", Options{}); err == nil {
+		t.Errorf("expected error without a path")
+	}
+
+	split := sample
+	split.Postings = []ledger.Posting{
+		{Account: "liabilities:capitalone", Amount: "-$3,096.00"},
+		{Account: ledger.Uncategorised, Amount: "$3,096.00"},
+	}
+	if err := NewHledgerCSV(&strings.Builder{}).Write(split); err == nil {
+		t.Errorf("expected error writing a split transaction")
+	}
+}
+
+func TestOpenChangedColumns(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "old.csv")
+	if err := os.WriteFile(old, []byte("date,status,payee\n2025-04-15,pending,Store\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open("csv", old, Options{}); err == nil {
+		t.Errorf("expected error appending to a CSV file with other columns")
+	}
+
+	path := filepath.Join(dir, "alerts.csv")
+	rules := "skip 1\nfields date, status, description, amount, account1, code, comment\n"
+	if err := os.WriteFile(path+".rules", []byte(rules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open("hledger-csv", path, Options{}); err == nil {
+		t.Errorf("expected error with rules naming other fields")
+	}
+}
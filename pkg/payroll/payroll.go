@@ -0,0 +1,145 @@
+// Package payroll turns pay stubs into split transactions of gross pay,
+// taxes, deductions and the net deposit.
+package payroll
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+)
+
+// Default accounts for the parts of a pay stub other than the net pay.
+const (
+	DefaultIncomeAccount    = "income:salary"
+	DefaultTaxAccount       = "expenses:taxes:payroll"
+	DefaultDeductionAccount = "expenses:payroll:deductions"
+)
+
+// Line is a named amount withheld from gross pay, written positive.
+type Line struct {
+	Name   string
+	Amount string
+}
+
+// Stub is a pay statement.
+type Stub struct {
+	Employer   string
+	Date       time.Time
+	Gross      string
+	Taxes      []Line
+	Deductions []Line
+	Net        string
+}
+
+// Accounts are where the parts of a stub are posted, see the defaults.
+type Accounts struct {
+	Income    string
+	Tax       string
+	Deduction string
+}
+
+// Layout names the rows of a payroll provider's stub. Rows with an amount
+// between gross and net pay are taxes when their label looks like one and
+// deductions otherwise.
+type Layout struct {
+	Gross []string
+	Net   []string
+	// Ignore lists rows with amounts that aren't withheld, such as hours or
+	// the totals of taxes and deductions.
+	Ignore []string
+}
+
+var (
+	expAmount = regexp.MustCompile(`^\(?-?\$-?\d{1,3}(?:,\d{3})*\.\d{2}\)?$`)
+	expTax    = regexp.MustCompile(`(?i)\btax|social security|oasdi|medicare|fica|\bs?di\b|\bfli\b|withholding`)
+)
+
+// Parse reads the label/value rows of a stub laid out as layout. It returns
+// false when the rows have no net pay.
+func Parse(fields email.Fields, layout Layout) (Stub, bool) {
+	var s Stub
+	for _, f := range fields {
+		value := strings.TrimSpace(f.Value)
+		if !expAmount.MatchString(value) {
+			continue
+		}
+		// withheld amounts are written negative or in parentheses
+		value = strings.NewReplacer("-", "", "(", "", ")", "").Replace(value)
+		label := strings.TrimSuffix(strings.TrimSpace(f.Label), ":")
+		switch {
+		case in(label, layout.Gross):
+			s.Gross = value
+		case in(label, layout.Net):
+			s.Net = value
+		case in(label, layout.Ignore), s.Gross == "", s.Net != "":
+		case expTax.MatchString(label):
+			s.Taxes = append(s.Taxes, Line{Name: label, Amount: value})
+		default:
+			s.Deductions = append(s.Deductions, Line{Name: label, Amount: value})
+		}
+	}
+	return s, s.Net != ""
+}
+
+// Transaction books the stub as deposited to account: the net pay to
+// account, gross pay from the income account and a posting for each tax and
+// deduction, commented with its name. What they don't account for is
+// posted to the deduction account. A stub without gross pay is booked as
+// net pay from the income account.
+func (s Stub) Transaction(account string, accounts Accounts) (ledger.Transaction, error) {
+	if accounts.Income == "" {
+		accounts.Income = DefaultIncomeAccount
+	}
+	if accounts.Tax == "" {
+		accounts.Tax = DefaultTaxAccount
+	}
+	if accounts.Deduction == "" {
+		accounts.Deduction = DefaultDeductionAccount
+	}
+	t := ledger.Transaction{
+		// payroll only emails once the pay is deposited
+		Status:    ledger.Cleared,
+		Payee:     s.Employer,
+		Amount:    s.Net,
+		Date:      s.Date,
+		Account:   account,
+		IsReceive: true,
+	}
+	gross := s.Gross
+	if gross == "" {
+		gross = s.Net
+	}
+	g, err := ledger.ParseAmount(gross)
+	if err != nil {
+		return t, err
+	}
+	t.Postings = []ledger.Posting{
+		{Account: account, Amount: s.Net},
+		{Account: accounts.Income, Amount: g.Neg().String()},
+	}
+	for _, l := range s.Taxes {
+		t.Postings = append(t.Postings, ledger.Posting{Account: accounts.Tax, Amount: l.Amount, Comment: l.Name})
+	}
+	for _, l := range s.Deductions {
+		t.Postings = append(t.Postings, ledger.Posting{Account: accounts.Deduction, Amount: l.Amount, Comment: l.Name})
+	}
+	err = t.Check()
+	if errors.Is(err, ledger.ErrUnbalanced) {
+		t.Postings = append(t.Postings, ledger.Posting{Account: accounts.Deduction, Comment: "other deductions"})
+		err = t.AutoBalance()
+	}
+	return t, err
+}
+
+func in(label string, labels []string) bool {
+	for _, l := range labels {
+		if strings.EqualFold(strings.Join(strings.Fields(l), " "), strings.Join(strings.Fields(label), " ")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package payroll
+
+import (
+	"testing"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	layout := Layout{Gross: []string{"Gross Pay"}, Net: []string{"Net Pay"}, Ignore: []string{"Total Taxes"}}
+	fields := email.Fields{
+		{Label: "Rate", Value: "$50.00"},
+		{Label: "Gross Pay:", Value: "$2,000.00"},
+		{Label: "OASDI", Value: "-$124.00"},
+		{Label: "Federal Withholding", Value: "($210.00)"},
+		{Label: "Total Taxes", Value: "-$334.00"},
+		{Label: "Dental", Value: "$12.00"},
+		{Label: "Net Pay", Value: "$1,654.00"},
+		{Label: "Savings ...9876", Value: "$1,654.00"},
+	}
+	s, ok := Parse(fields, layout)
+	assert.True(t, ok)
+	assert.Equal(t, Stub{
+		Gross:      "$2,000.00",
+		Taxes:      []Line{{Name: "OASDI", Amount: "$124.00"}, {Name: "Federal Withholding", Amount: "$210.00"}},
+		Deductions: []Line{{Name: "Dental", Amount: "$12.00"}},
+		Net:        "$1,654.00",
+	}, s)
+
+	_, ok = Parse(fields[:6], layout)
+	assert.False(t, ok)
+}
+
+func TestTransactionNetOnly(t *testing.T) {
+	tx, err := Stub{Employer: "ACME", Net: "$1,500.00"}.Transaction("assets:checking", Accounts{})
+	assert.NoError(t, err)
+	assert.Equal(t, "-$1500.00", tx.Postings[1].Amount)
+	assert.Equal(t, DefaultIncomeAccount, tx.Postings[1].Account)
+	assert.Len(t, tx.Postings, 2)
+	assert.NoError(t, tx.Check())
+}
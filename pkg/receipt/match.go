@@ -0,0 +1,194 @@
+package receipt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+)
+
+type Config struct {
+	// StateFile keeps unmatched receipts and card transactions between runs.
+	// Matching is disabled when it is empty.
+	StateFile string `yaml:"stateFile"`
+	// WindowDays is how many days apart a receipt and the card transaction
+	// paying for it may be dated, 3 by default.
+	WindowDays int `yaml:"windowDays"`
+	// ExpireDays is how long receipts wait for their card transaction, 14
+	// by default. Receipts that never match are then written as paid from
+	// their own account.
+	ExpireDays int `yaml:"expireDays"`
+}
+
+// Charge is a card transaction a receipt may itemise, or a deposit a pay
+// stub may.
+type Charge struct {
+	MessageID string    `json:"message_id"`
+	Account   string    `json:"account"`
+	Payee     string    `json:"payee"`
+	Amount    string    `json:"amount"`
+	Date      time.Time `json:"date"`
+	Receive   bool      `json:"receive,omitempty"`
+}
+
+type state struct {
+	Receipts []ledger.Transaction `json:"receipts"`
+	Charges  []Charge             `json:"charges"`
+}
+
+// Matcher pairs receipts with card transactions of the same amount, and pay
+// stubs with the deposits of their net pay. The card transaction or deposit
+// is written as usual and balanced by Uncategorised; the receipt is then
+// written against Uncategorised instead of its own account, moving the
+// amount to the itemised postings.
+type Matcher struct {
+	conf  Config
+	state state
+}
+
+// New loads the state file, which doesn't need to exist yet.
+func New(conf Config) (*Matcher, error) {
+	if conf.WindowDays == 0 {
+		conf.WindowDays = 3
+	}
+	if conf.ExpireDays == 0 {
+		conf.ExpireDays = 14
+	}
+	m := &Matcher{conf: conf}
+	b, err := os.ReadFile(conf.StateFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &m.state); err != nil {
+		return nil, fmt.Errorf("invalid receipt state %s: %w", conf.StateFile, err)
+	}
+	return m, nil
+}
+
+// Process returns the transactions to write for t, which is a receipt when
+// receipt is set. A receipt matching an earlier charge is written against
+// Uncategorised; otherwise it is held until its charge comes or it expires.
+// A charge is written as is, followed by the receipt it matches if any,
+// and remembered for receipts that come later. The state file is saved
+// whenever it changes.
+func (m *Matcher) Process(t ledger.Transaction, receipt bool) ([]ledger.Transaction, error) {
+	out := m.expire(t.Date)
+	if receipt {
+		if i := m.matchCharge(t); i >= 0 {
+			c := m.state.Charges[i]
+			m.state.Charges = slices.Delete(m.state.Charges, i, i+1)
+			return append(out, itemise(t, c)), m.save()
+		}
+		m.state.Receipts = append(m.state.Receipts, t)
+		return out, m.save()
+	}
+
+	out = append(out, t)
+	c := Charge{MessageID: t.Source.MessageID, Account: t.Account, Payee: t.Payee, Amount: t.Amount, Date: t.Date, Receive: t.IsReceive}
+	if i := m.matchReceipt(c); i >= 0 {
+		r := m.state.Receipts[i]
+		m.state.Receipts = slices.Delete(m.state.Receipts, i, i+1)
+		return append(out, itemise(r, c)), m.save()
+	}
+	m.state.Charges = append(m.state.Charges, c)
+	return out, m.save()
+}
+
+// itemise rewrites the posting of receipt r to its own account as one to
+// Uncategorised, which balanced charge c when it was written.
+func itemise(r ledger.Transaction, c Charge) ledger.Transaction {
+	ps := slices.Clone(r.Postings)
+	for i := range ps {
+		if ps[i].Account == r.Account {
+			ps[i].Account = ledger.Uncategorised
+		}
+	}
+	r.Postings = ps
+	r.Account = ledger.Uncategorised
+	note := fmt.Sprintf("itemises %s %s %s", c.Date.Format("2006-01-02"), c.Payee, c.Amount)
+	if c.MessageID != "" {
+		note += " (" + c.MessageID + ")"
+	}
+	if r.Note != "" {
+		note = r.Note + "; " + note
+	}
+	r.Note = note
+	return r
+}
+
+// matchCharge returns the index of the oldest charge paying for receipt r,
+// or -1.
+func (m *Matcher) matchCharge(r ledger.Transaction) int {
+	for i, c := range m.state.Charges {
+		if m.matches(r, c) {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchReceipt returns the index of the oldest receipt charge c pays for,
+// or -1.
+func (m *Matcher) matchReceipt(c Charge) int {
+	for i, r := range m.state.Receipts {
+		if m.matches(r, c) {
+			return i
+		}
+	}
+	return -1
+}
+
+// matches reports whether c pays for receipt r: money spent for a receipt,
+// money received for a pay stub.
+func (m *Matcher) matches(r ledger.Transaction, c Charge) bool {
+	if r.IsReceive != c.Receive {
+		return false
+	}
+	window := time.Duration(m.conf.WindowDays) * 24 * time.Hour
+	if d := c.Date.Sub(r.Date); d > window || d < -window {
+		return false
+	}
+	ra, err := ledger.ParseAmount(r.Amount)
+	if err != nil {
+		return false
+	}
+	ca, err := ledger.ParseAmount(c.Amount)
+	if err != nil {
+		return false
+	}
+	return ra.Commodity == ca.Commodity && ra.Abs().Cmp(ca.Abs()) == 0
+}
+
+// expire drops charges older than the expiry relative to now and returns
+// the receipts that expired, to be written as they are.
+func (m *Matcher) expire(now time.Time) []ledger.Transaction {
+	cutoff := now.AddDate(0, 0, -m.conf.ExpireDays)
+	m.state.Charges = slices.DeleteFunc(m.state.Charges, func(c Charge) bool {
+		return c.Date.Before(cutoff)
+	})
+	var expired []ledger.Transaction
+	m.state.Receipts = slices.DeleteFunc(m.state.Receipts, func(r ledger.Transaction) bool {
+		if r.Date.Before(cutoff) {
+			expired = append(expired, r)
+			return true
+		}
+		return false
+	})
+	return expired
+}
+
+func (m *Matcher) save() error {
+	b, err := json.MarshalIndent(m.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.conf.StateFile, b, 0o600)
+}
@@ -0,0 +1,127 @@
+package receipt
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/stretchr/testify/assert"
+)
+
+func charge(payee, amt string, day int) ledger.Transaction {
+	return ledger.Transaction{
+		Status:  ledger.Pending,
+		Payee:   payee,
+		Amount:  amt,
+		Account: "liabilities:chase",
+		Date:    time.Date(2025, 5, day, 0, 0, 0, 0, time.UTC),
+		Source:  ledger.Source{MessageID: "card" + amt},
+	}
+}
+
+func order(amt string, day int) ledger.Transaction {
+	return ledger.Transaction{
+		ID:      "112-1234567-1234567",
+		Status:  ledger.Cleared,
+		Payee:   "Amazon",
+		Amount:  amt,
+		Account: "liabilities:chase",
+		Date:    time.Date(2025, 5, day, 0, 0, 0, 0, time.UTC),
+		Postings: []ledger.Posting{
+			{Account: "liabilities:chase", Amount: "-" + amt},
+			{Account: ledger.Uncategorised, Amount: amt, Comment: "Book"},
+		},
+		Source: ledger.Source{MessageID: "order" + amt},
+	}
+}
+
+func itemised(amt string, day int, note string) ledger.Transaction {
+	t := order(amt, day)
+	t.Account = ledger.Uncategorised
+	t.Postings[0].Account = ledger.Uncategorised
+	t.Note = note
+	return t
+}
+
+func TestProcess(t *testing.T) {
+	m, err := New(Config{StateFile: filepath.Join(t.TempDir(), "receipts.json")})
+	assert.NoError(t, err)
+
+	// charge first, then its receipt
+	out, err := m.Process(charge("AMAZON MKTPLACE", "$53.12", 5), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []ledger.Transaction{charge("AMAZON MKTPLACE", "$53.12", 5)}, out)
+	out, err = m.Process(order("$53.12", 4), true)
+	assert.NoError(t, err)
+	assert.Equal(t, []ledger.Transaction{itemised("$53.12", 4, "itemises 2025-05-05 AMAZON MKTPLACE $53.12 (card$53.12)")}, out)
+
+	// receipt first, held until its charge
+	out, err = m.Process(order("$20.00", 6), true)
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+	out, err = m.Process(charge("AMZN Mktp US", "$9.99", 7), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []ledger.Transaction{charge("AMZN Mktp US", "$9.99", 7)}, out)
+
+	// the state survives between runs
+	m, err = New(m.conf)
+	assert.NoError(t, err)
+	out, err = m.Process(charge("AMZN Mktp US", "$20.00", 8), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []ledger.Transaction{
+		charge("AMZN Mktp US", "$20.00", 8),
+		itemised("$20.00", 6, "itemises 2025-05-08 AMZN Mktp US $20.00 (card$20.00)"),
+	}, out)
+
+	// a receipt that never matches is written as paid from its account
+	out, err = m.Process(order("$15.00", 9), true)
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+	out, err = m.Process(charge("GROCER", "$30.00", 24), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []ledger.Transaction{order("$15.00", 9), charge("GROCER", "$30.00", 24)}, out)
+}
+
+func TestProcessPayStub(t *testing.T) {
+	m, err := New(Config{StateFile: filepath.Join(t.TempDir(), "receipts.json")})
+	assert.NoError(t, err)
+
+	deposit := ledger.Transaction{
+		Status: ledger.Cleared, Payee: "ACME CORP PAYROLL", Amount: "$2,654.25", IsReceive: true,
+		Account: "assets:checking", Date: time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC),
+		Source: ledger.Source{MessageID: "deposit"},
+	}
+	stub := ledger.Transaction{
+		Status: ledger.Cleared, Payee: "ACME CORP", Amount: "$2,654.25", IsReceive: true,
+		Account: "assets:checking", Date: time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC),
+		Postings: []ledger.Posting{
+			{Account: "assets:checking", Amount: "$2,654.25"},
+			{Account: "income:salary", Amount: "-$3000.00"},
+			{Account: "expenses:taxes", Amount: "$345.75"},
+		},
+	}
+
+	// a card charge of the same amount doesn't pay a stub
+	out, err := m.Process(charge("ELECTRONICS", "$2,654.25", 1), false)
+	assert.NoError(t, err)
+	assert.Len(t, out, 1)
+
+	out, err = m.Process(deposit, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []ledger.Transaction{deposit}, out)
+	out, err = m.Process(stub, true)
+	assert.NoError(t, err)
+	want := stub
+	want.Account = ledger.Uncategorised
+	want.Postings = []ledger.Posting{
+		{Account: ledger.Uncategorised, Amount: "$2,654.25"},
+		{Account: "income:salary", Amount: "-$3000.00"},
+		{Account: "expenses:taxes", Amount: "$345.75"},
+	}
+	want.Note = "itemises 2025-05-02 ACME CORP PAYROLL $2,654.25 (deposit)"
+	assert.Equal(t, []ledger.Transaction{want}, out)
+
+	// the card charge is still waiting for its receipt
+	assert.Len(t, m.state.Charges, 1)
+}
@@ -0,0 +1,202 @@
+// Package receipt turns itemised merchant receipts into split transactions
+// and matches them with the card transactions that paid for them.
+package receipt
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+)
+
+// Default accounts for the parts of a receipt other than its items, which
+// are left uncategorised.
+const (
+	DefaultTaxAccount = "expenses:taxes:sales"
+	DefaultTipAccount = "expenses:tips"
+	DefaultFeeAccount = "expenses:fees:delivery"
+)
+
+// Item is a line of a receipt. Amount is the price of the whole line, for
+// all of Quantity.
+type Item struct {
+	Name     string
+	Quantity int
+	Amount   string
+}
+
+// Receipt is an order confirmation or trip receipt. Amounts are written as
+// in the email and are all positive; Discount is taken off the total.
+type Receipt struct {
+	Merchant string
+	Order    string
+	Note     string
+	Date     time.Time
+	Items    []Item
+	Fees     []Item
+	Discount string
+	Tax      string
+	Tip      string
+	Total    string
+}
+
+// Accounts are where the parts of a receipt are posted, see the defaults.
+type Accounts struct {
+	Tax string
+	Tip string
+	Fee string
+}
+
+// Layout names the summary rows of a merchant's receipt. Rows whose value
+// is an amount and that are none of these are items.
+type Layout struct {
+	Subtotal []string
+	Fees     []string
+	Discount []string
+	Tax      []string
+	Tip      []string
+	Total    []string
+	// Ignore lists rows with amounts that aren't part of the order, such as
+	// gift card balances.
+	Ignore []string
+}
+
+var (
+	expAmount   = regexp.MustCompile(`^-?\$-?\d{1,3}(?:,\d{3})*\.\d{2}$`)
+	expQuantity = regexp.MustCompile(`^(?:(\d+)\s*[x×]\s+(.+)|(.+?)\s*\(?(?:Qty|Quantity):\s*(\d+)\)?)$`)
+	// expPayment matches the rows listing how the order was paid, "Visa
+	// ••••1234", which repeat the total
+	expPayment = regexp.MustCompile(`(?i)•{2,}|\*{2,}|\bending in\b|^(?:visa|mastercard|amex|american express|discover|paypal|apple pay|google pay)\b`)
+)
+
+// Parse reads the label/value rows of a receipt laid out as layout, skipping
+// the rows naming the card it was paid with. It returns false when the rows
+// have no total.
+func Parse(fields email.Fields, layout Layout) (Receipt, bool) {
+	var r Receipt
+	for _, f := range fields {
+		value := strings.TrimSpace(f.Value)
+		if !expAmount.MatchString(value) {
+			continue
+		}
+		// discounts are sometimes written negative
+		value = strings.Replace(value, "-", "", 1)
+		label := normalize(f.Label)
+		switch {
+		case in(label, layout.Total):
+			r.Total = value
+		case in(label, layout.Subtotal), in(label, layout.Ignore), expPayment.MatchString(label):
+		case in(label, layout.Tax):
+			r.Tax = value
+		case in(label, layout.Tip):
+			r.Tip = value
+		case in(label, layout.Discount):
+			r.Discount = value
+		case in(label, layout.Fees):
+			r.Fees = append(r.Fees, Item{Name: strings.TrimSuffix(strings.TrimSpace(f.Label), ":"), Quantity: 1, Amount: value})
+		default:
+			r.Items = append(r.Items, item(f.Label, value))
+		}
+	}
+	return r, r.Total != ""
+}
+
+// item reads the quantity written before or after the name of a line,
+// "2 × Burrito Bowl" or "USB-C Cable (Qty: 2)".
+func item(label, amount string) Item {
+	it := Item{Name: strings.TrimSpace(label), Quantity: 1, Amount: amount}
+	if m := expQuantity.FindStringSubmatch(it.Name); m != nil {
+		if m[1] != "" {
+			it.Name, it.Quantity = m[2], atoi(m[1])
+		} else {
+			it.Name, it.Quantity = m[3], atoi(m[4])
+		}
+	}
+	return it
+}
+
+func atoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n == 0 {
+		return 1
+	}
+	return n
+}
+
+// Transaction books the receipt as paid from account: a posting of the total
+// to account and one for each item, fee, discount, tax and tip. Items and
+// discounts are uncategorised and commented with the item's name. What the
+// parts don't account for, such as items the email doesn't list, is posted
+// to Uncategorised as well.
+func (r Receipt) Transaction(account string, accounts Accounts) (ledger.Transaction, error) {
+	if accounts.Tax == "" {
+		accounts.Tax = DefaultTaxAccount
+	}
+	if accounts.Tip == "" {
+		accounts.Tip = DefaultTipAccount
+	}
+	if accounts.Fee == "" {
+		accounts.Fee = DefaultFeeAccount
+	}
+	t := ledger.Transaction{
+		ID:      r.Order,
+		Status:  ledger.Cleared,
+		Payee:   r.Merchant,
+		Amount:  r.Total,
+		Note:    r.Note,
+		Date:    r.Date,
+		Account: account,
+	}
+	total, err := ledger.ParseAmount(r.Total)
+	if err != nil {
+		return t, err
+	}
+	t.Postings = []ledger.Posting{{Account: account, Amount: total.Neg().String()}}
+	for _, it := range r.Items {
+		comment := it.Name
+		if it.Quantity > 1 {
+			comment = fmt.Sprintf("%d × %s", it.Quantity, it.Name)
+		}
+		t.Postings = append(t.Postings, ledger.Posting{Account: ledger.Uncategorised, Amount: it.Amount, Comment: comment})
+	}
+	for _, f := range r.Fees {
+		t.Postings = append(t.Postings, ledger.Posting{Account: accounts.Fee, Amount: f.Amount, Comment: f.Name})
+	}
+	if r.Discount != "" {
+		d, err := ledger.ParseAmount(r.Discount)
+		if err != nil {
+			return t, err
+		}
+		t.Postings = append(t.Postings, ledger.Posting{Account: ledger.Uncategorised, Amount: d.Neg().String(), Comment: "discount"})
+	}
+	if r.Tax != "" {
+		t.Postings = append(t.Postings, ledger.Posting{Account: accounts.Tax, Amount: r.Tax})
+	}
+	if r.Tip != "" {
+		t.Postings = append(t.Postings, ledger.Posting{Account: accounts.Tip, Amount: r.Tip})
+	}
+	err = t.Check()
+	if errors.Is(err, ledger.ErrUnbalanced) {
+		t.Postings = append(t.Postings, ledger.Posting{Account: ledger.Uncategorised, Comment: "not itemised"})
+		err = t.AutoBalance()
+	}
+	return t, err
+}
+
+func in(label string, labels []string) bool {
+	for _, l := range labels {
+		if normalize(l) == label {
+			return true
+		}
+	}
+	return false
+}
+
+func normalize(l string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.Join(strings.Fields(l), " "), ":"))
+}
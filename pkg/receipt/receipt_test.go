@@ -0,0 +1,116 @@
+package receipt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/stretchr/testify/assert"
+)
+
+var testLayout = Layout{
+	Subtotal: []string{"Subtotal"},
+	Fees:     []string{"Delivery Fee", "Service Fee"},
+	Discount: []string{"Promotion"},
+	Tax:      []string{"Taxes"},
+	Tip:      []string{"Tip"},
+	Total:    []string{"Total"},
+	Ignore:   []string{"Gift Card"},
+}
+
+func TestParse(t *testing.T) {
+	fields := email.Fields{
+		{Label: "Order", Value: "#5521"},
+		{Label: "2 × Burrito Bowl", Value: "$21.00"},
+		{Label: "Chips (Qty: 3)", Value: "$6.75"},
+		{Label: "Lemonade", Value: "$3.25"},
+		{Label: "Subtotal", Value: "$31.00"},
+		{Label: "Delivery Fee:", Value: "$1.99"},
+		{Label: "Service Fee", Value: "$3.10"},
+		{Label: "Promotion", Value: "-$5.00"},
+		{Label: "Taxes", Value: "$2.48"},
+		{Label: "Tip", Value: "$4.00"},
+		{Label: "Total", Value: "$37.57"},
+		{Label: "Visa ending in 1234", Value: "$37.57"},
+		{Label: "Gift Card", Value: "$10.00"},
+	}
+	r, ok := Parse(fields, testLayout)
+	assert.True(t, ok)
+	assert.Equal(t, Receipt{
+		Items: []Item{
+			{Name: "Burrito Bowl", Quantity: 2, Amount: "$21.00"},
+			{Name: "Chips", Quantity: 3, Amount: "$6.75"},
+			{Name: "Lemonade", Quantity: 1, Amount: "$3.25"},
+		},
+		Fees: []Item{
+			{Name: "Delivery Fee", Quantity: 1, Amount: "$1.99"},
+			{Name: "Service Fee", Quantity: 1, Amount: "$3.10"},
+		},
+		Discount: "$5.00",
+		Tax:      "$2.48",
+		Tip:      "$4.00",
+		Total:    "$37.57",
+	}, r)
+
+	_, ok = Parse(fields[:3], testLayout)
+	assert.False(t, ok)
+}
+
+func TestTransaction(t *testing.T) {
+	date := time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		receipt  Receipt
+		accounts Accounts
+		expected []ledger.Posting
+	}{
+		{
+			name: "itemised",
+			receipt: Receipt{
+				Items:    []Item{{Name: "Burrito Bowl", Quantity: 2, Amount: "$21.00"}},
+				Fees:     []Item{{Name: "Delivery Fee", Quantity: 1, Amount: "$1.99"}},
+				Discount: "$5.00",
+				Tax:      "$1.68",
+				Tip:      "$3.00",
+				Total:    "$22.67",
+			},
+			accounts: Accounts{Tip: "expenses:food:tips"},
+			expected: []ledger.Posting{
+				{Account: "liabilities:card", Amount: "-$22.67"},
+				{Account: ledger.Uncategorised, Amount: "$21.00", Comment: "2 × Burrito Bowl"},
+				{Account: DefaultFeeAccount, Amount: "$1.99", Comment: "Delivery Fee"},
+				{Account: ledger.Uncategorised, Amount: "-$5.00", Comment: "discount"},
+				{Account: DefaultTaxAccount, Amount: "$1.68"},
+				{Account: "expenses:food:tips", Amount: "$3.00"},
+			},
+		},
+		{
+			name: "items missing from the email",
+			receipt: Receipt{
+				Items: []Item{{Name: "Book", Quantity: 1, Amount: "$20.00"}},
+				Tax:   "$1.60",
+				Total: "$31.60",
+			},
+			expected: []ledger.Posting{
+				{Account: "liabilities:card", Amount: "-$31.60"},
+				{Account: ledger.Uncategorised, Amount: "$20.00", Comment: "Book"},
+				{Account: DefaultTaxAccount, Amount: "$1.60"},
+				{Account: ledger.Uncategorised, Amount: "$10.00", Comment: "not itemised"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.receipt.Merchant, tc.receipt.Order, tc.receipt.Date = "DoorDash", "5521", date
+			tx, err := tc.receipt.Transaction("liabilities:card", tc.accounts)
+			assert.NoError(t, err)
+			assert.Equal(t, ledger.Transaction{
+				ID: "5521", Status: ledger.Cleared, Payee: "DoorDash", Amount: tc.receipt.Total, Date: date,
+				Account: "liabilities:card", Postings: tc.expected,
+			}, tx)
+			assert.NoError(t, tx.Check())
+		})
+	}
+}
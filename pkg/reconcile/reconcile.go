@@ -0,0 +1,292 @@
+// Package reconcile matches pending authorizations from card alerts against
+// the transactions that later post for them.
+package reconcile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+)
+
+// Modes for emitting a reconciled transaction.
+const (
+	// Adjust emits a single transaction for the difference between the
+	// pending and posted amounts, or nothing when they are equal.
+	Adjust = "adjust"
+	// Replace emits a reversal of the pending entry followed by the posted
+	// transaction.
+	Replace = "replace"
+)
+
+type Config struct {
+	// StateFile keeps the pending entries between runs. Reconciliation is
+	// disabled when it is empty.
+	StateFile string `yaml:"stateFile"`
+	// Mode is adjust (default) or replace.
+	Mode string `yaml:"mode"`
+	// WindowDays is how many days after the authorization the posted
+	// transaction may be dated, 7 by default.
+	WindowDays int `yaml:"windowDays"`
+	// Tolerance is how far in percent the posted amount may differ from the
+	// pending one, 25 by default to cover tips; 0 requires the same amount.
+	Tolerance *int `yaml:"tolerance"`
+	// Holds are placeholder authorization amounts, such as fuel pump
+	// pre-authorizations, that match a posted transaction of any amount.
+	Holds []string `yaml:"holds"`
+	// ExpireDays drops pending entries that never matched, 30 by default.
+	ExpireDays int `yaml:"expireDays"`
+	// Now is the time of the run pending entries expire relative to,
+	// time.Now() by default.
+	Now time.Time `yaml:"-"`
+}
+
+// Entry is a pending transaction waiting for its posted counterpart.
+type Entry struct {
+	MessageID string    `json:"message_id"`
+	ID        string    `json:"id,omitempty"`
+	Account   string    `json:"account"`
+	Payee     string    `json:"payee"`
+	Amount    string    `json:"amount"`
+	IsReceive bool      `json:"is_receive"`
+	Date      time.Time `json:"date"`
+}
+
+type state struct {
+	Pending []Entry `json:"pending"`
+}
+
+// Reconciler tracks pending entries in the state file.
+type Reconciler struct {
+	conf  Config
+	holds []ledger.Amount
+	state state
+}
+
+// New loads the state file, which doesn't need to exist yet.
+func New(conf Config) (*Reconciler, error) {
+	if conf.Mode == "" {
+		conf.Mode = Adjust
+	}
+	if conf.Mode != Adjust && conf.Mode != Replace {
+		return nil, fmt.Errorf("unknown reconcile mode %q", conf.Mode)
+	}
+	if conf.WindowDays == 0 {
+		conf.WindowDays = 7
+	}
+	if conf.Tolerance == nil {
+		tolerance := 25
+		conf.Tolerance = &tolerance
+	}
+	if conf.ExpireDays == 0 {
+		conf.ExpireDays = 30
+	}
+	if conf.Now.IsZero() {
+		conf.Now = time.Now()
+	}
+	r := &Reconciler{conf: conf}
+	for _, h := range conf.Holds {
+		a, err := ledger.ParseAmount(h)
+		if err != nil {
+			return nil, fmt.Errorf("reconcile holds: %w", err)
+		}
+		r.holds = append(r.holds, a)
+	}
+
+	b, err := os.ReadFile(conf.StateFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &r.state); err != nil {
+		return nil, fmt.Errorf("invalid reconcile state %s: %w", conf.StateFile, err)
+	}
+	return r, nil
+}
+
+// Process returns the transactions to write for t. Pending transactions are
+// remembered and written as is. A posted transaction that matches a pending
+// entry on the same account, by merchant, date window and amount tolerance,
+// is turned into corrections according to the mode; other transactions are
+// written as is. The state file is saved whenever it changes.
+func (r *Reconciler) Process(t ledger.Transaction) ([]ledger.Transaction, error) {
+	changed := r.expire(r.conf.Now)
+	if t.Status == ledger.Pending {
+		r.state.Pending = append(r.state.Pending, Entry{
+			MessageID: t.Source.MessageID,
+			ID:        t.ID,
+			Account:   t.Account,
+			Payee:     t.Payee,
+			Amount:    t.Amount,
+			IsReceive: t.IsReceive,
+			Date:      t.Date,
+		})
+		return []ledger.Transaction{t}, r.save()
+	}
+
+	i, err := r.match(t)
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 {
+		if changed {
+			return []ledger.Transaction{t}, r.save()
+		}
+		return []ledger.Transaction{t}, nil
+	}
+	e := r.state.Pending[i]
+	r.state.Pending = slices.Delete(r.state.Pending, i, i+1)
+
+	out, err := r.corrections(e, t)
+	if err != nil {
+		return nil, err
+	}
+	return out, r.save()
+}
+
+func (r *Reconciler) corrections(e Entry, t ledger.Transaction) ([]ledger.Transaction, error) {
+	pending := e.transaction()
+	ref := fmt.Sprintf("pending %s %s", e.Date.Format("2006-01-02"), e.Amount)
+	if e.MessageID != "" {
+		ref += " (" + e.MessageID + ")"
+	}
+
+	if r.conf.Mode == Replace {
+		rev := pending
+		rev.IsReceive = !rev.IsReceive
+		rev.Date = t.Date
+		rev.Status = ledger.Cleared
+		rev.Note = "reverses " + ref
+		rev.Source = t.Source
+		return []ledger.Transaction{rev, t}, nil
+	}
+
+	posted, err := t.SignedAmount()
+	if err != nil {
+		return nil, err
+	}
+	held, err := pending.SignedAmount()
+	if err != nil {
+		return nil, err
+	}
+	diff, err := posted.Sub(held)
+	if err != nil {
+		return nil, err
+	}
+	if diff.IsZero() {
+		return nil, nil
+	}
+	// only the difference is booked, not the postings or tags of t, which
+	// the pending entry already recorded
+	adj := ledger.Transaction{
+		Status:    ledger.Cleared,
+		Payee:     t.Payee,
+		Amount:    diff.Abs().String(),
+		Date:      t.Date,
+		Account:   t.Account,
+		IsReceive: diff.Quantity > 0,
+		Note:      "adjusts " + ref,
+		Source:    t.Source,
+	}
+	if t.Note != "" {
+		adj.Note += "; " + t.Note
+	}
+	return []ledger.Transaction{adj}, nil
+}
+
+// match returns the index of the oldest pending entry matching t, or -1.
+func (r *Reconciler) match(t ledger.Transaction) (int, error) {
+	posted, err := t.SignedAmount()
+	if err != nil {
+		return -1, err
+	}
+	for i, e := range r.state.Pending {
+		if e.Account != t.Account || !samePayee(e.Payee, t.Payee) {
+			continue
+		}
+		if t.Date.Before(e.Date.AddDate(0, 0, -1)) || t.Date.After(e.Date.AddDate(0, 0, r.conf.WindowDays)) {
+			continue
+		}
+		held, err := e.transaction().SignedAmount()
+		if err != nil {
+			return -1, err
+		}
+		if r.withinTolerance(held, posted) {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+func (r *Reconciler) withinTolerance(held, posted ledger.Amount) bool {
+	// a refund never settles a purchase
+	if held.Commodity != posted.Commodity || (held.Quantity < 0) != (posted.Quantity < 0) {
+		return false
+	}
+	for _, h := range r.holds {
+		if h.Commodity == held.Commodity && h.Cmp(held.Abs()) == 0 {
+			return true
+		}
+	}
+	diff, _ := posted.Sub(held)
+	// 100*|diff| <= tolerance*|held|
+	diff = ledger.Amount{Quantity: diff.Abs().Quantity * 100, Scale: diff.Scale}
+	limit := ledger.Amount{Quantity: held.Abs().Quantity * int64(*r.conf.Tolerance), Scale: held.Scale}
+	return diff.Cmp(limit) <= 0
+}
+
+// expire drops pending entries older than the expiry relative to now.
+func (r *Reconciler) expire(now time.Time) bool {
+	n := len(r.state.Pending)
+	cutoff := now.AddDate(0, 0, -r.conf.ExpireDays)
+	r.state.Pending = slices.DeleteFunc(r.state.Pending, func(e Entry) bool {
+		return e.Date.Before(cutoff)
+	})
+	return len(r.state.Pending) != n
+}
+
+func (r *Reconciler) save() error {
+	b, err := json.MarshalIndent(r.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.conf.StateFile, b, 0o600)
+}
+
+func (e Entry) transaction() ledger.Transaction {
+	return ledger.Transaction{
+		ID:        e.ID,
+		Payee:     e.Payee,
+		Amount:    e.Amount,
+		Date:      e.Date,
+		Account:   e.Account,
+		IsReceive: e.IsReceive,
+	}
+}
+
+var nonLetters = regexp.MustCompile(`[^A-Z]+`)
+
+// samePayee compares merchant names loosely since store numbers, punctuation
+// and locations differ between the authorization and posted records, e.g.
+// "HOLIDAY STATIONS 3826" and "Holiday Stations #3826 MN".
+func samePayee(a, b string) bool {
+	a = strings.TrimSpace(nonLetters.ReplaceAllString(strings.ToUpper(a), " "))
+	b = strings.TrimSpace(nonLetters.ReplaceAllString(strings.ToUpper(b), " "))
+	if a == "" || b == "" {
+		return false
+	}
+	if strings.HasPrefix(a, b) || strings.HasPrefix(b, a) {
+		return true
+	}
+	fa, fb := strings.Fields(a), strings.Fields(b)
+	return len(fa[0]) >= 4 && fa[0] == fb[0]
+}
@@ -0,0 +1,205 @@
+package reconcile
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/stretchr/testify/assert"
+)
+
+// now is when the tests run as far as expiry is concerned.
+var now = time.Date(2025, 8, 25, 0, 0, 0, 0, time.UTC)
+
+func pending(payee, amt string, day int) ledger.Transaction {
+	return ledger.Transaction{
+		Status:  ledger.Pending,
+		Payee:   payee,
+		Amount:  amt,
+		Account: "liabilities:discover",
+		Date:    time.Date(2025, 8, day, 0, 0, 0, 0, time.UTC),
+		Source:  ledger.Source{MessageID: "auth" + amt},
+	}
+}
+
+func posted(payee, amt string, day int) ledger.Transaction {
+	t := pending(payee, amt, day)
+	t.Status = ledger.Cleared
+	t.Source.MessageID = "post" + amt
+	return t
+}
+
+func TestProcess(t *testing.T) {
+	testCases := []struct {
+		name     string
+		conf     Config
+		pending  ledger.Transaction
+		posted   ledger.Transaction
+		expected []ledger.Transaction
+	}{
+		{
+			name:     "same amount",
+			pending:  pending("COFFEE SHOP 12", "$4.50", 18),
+			posted:   posted("Coffee Shop #12", "$4.50", 19),
+			expected: nil,
+		},
+		{
+			name:    "tip within tolerance",
+			pending: pending("BISTRO", "$40.00", 18),
+			posted:  posted("BISTRO", "$48.00", 20),
+			expected: []ledger.Transaction{{
+				Status:  ledger.Cleared,
+				Payee:   "BISTRO",
+				Amount:  "$8.00",
+				Account: "liabilities:discover",
+				Date:    time.Date(2025, 8, 20, 0, 0, 0, 0, time.UTC),
+				Note:    "adjusts pending 2025-08-18 $40.00 (auth$40.00)",
+				Source:  ledger.Source{MessageID: "post$48.00"},
+			}},
+		},
+		{
+			name:    "fuel hold",
+			conf:    Config{Holds: []string{"$1.00"}},
+			pending: pending("HOLIDAY STATIONS 3826", "$1.00", 18),
+			posted:  posted("HOLIDAY STATIONS #3826 MN", "$45.12", 19),
+			expected: []ledger.Transaction{{
+				Status:  ledger.Cleared,
+				Payee:   "HOLIDAY STATIONS #3826 MN",
+				Amount:  "$44.12",
+				Account: "liabilities:discover",
+				Date:    time.Date(2025, 8, 19, 0, 0, 0, 0, time.UTC),
+				Note:    "adjusts pending 2025-08-18 $1.00 (auth$1.00)",
+				Source:  ledger.Source{MessageID: "post$45.12"},
+			}},
+		},
+		{
+			name:    "replace",
+			conf:    Config{Mode: Replace},
+			pending: pending("BISTRO", "$40.00", 18),
+			posted:  posted("BISTRO", "$38.00", 20),
+			expected: []ledger.Transaction{
+				{
+					Status:    ledger.Cleared,
+					Payee:     "BISTRO",
+					Amount:    "$40.00",
+					IsReceive: true,
+					Account:   "liabilities:discover",
+					Date:      time.Date(2025, 8, 20, 0, 0, 0, 0, time.UTC),
+					Note:      "reverses pending 2025-08-18 $40.00 (auth$40.00)",
+					Source:    ledger.Source{MessageID: "post$38.00"},
+				},
+				posted("BISTRO", "$38.00", 20),
+			},
+		},
+		{
+			name:    "split posted transaction",
+			pending: pending("PAYPAL *SHOP", "$40.00", 18),
+			posted: func() ledger.Transaction {
+				t := posted("PAYPAL *SHOP", "$42.00", 19)
+				t.Tags = map[string]string{"funding": "Visa"}
+				t.Postings = []ledger.Posting{
+					{Account: "liabilities:discover", Amount: "-$42.00"},
+					{Account: ledger.Uncategorised, Amount: "$40.00"},
+					{Account: "expenses:fees", Amount: "$2.00"},
+				}
+				return t
+			}(),
+			expected: []ledger.Transaction{{
+				Status:  ledger.Cleared,
+				Payee:   "PAYPAL *SHOP",
+				Amount:  "$2.00",
+				Account: "liabilities:discover",
+				Date:    time.Date(2025, 8, 19, 0, 0, 0, 0, time.UTC),
+				Note:    "adjusts pending 2025-08-18 $40.00 (auth$40.00)",
+				Source:  ledger.Source{MessageID: "post$42.00"},
+			}},
+		},
+		{
+			name:     "outside tolerance",
+			pending:  pending("BISTRO", "$40.00", 18),
+			posted:   posted("BISTRO", "$80.00", 20),
+			expected: []ledger.Transaction{posted("BISTRO", "$80.00", 20)},
+		},
+		{
+			name:     "zero tolerance",
+			conf:     Config{Tolerance: new(int)},
+			pending:  pending("BISTRO", "$40.00", 18),
+			posted:   posted("BISTRO", "$40.01", 19),
+			expected: []ledger.Transaction{posted("BISTRO", "$40.01", 19)},
+		},
+		{
+			name:     "outside window",
+			pending:  pending("BISTRO", "$40.00", 1),
+			posted:   posted("BISTRO", "$40.00", 20),
+			expected: []ledger.Transaction{posted("BISTRO", "$40.00", 20)},
+		},
+		{
+			name:     "different merchant",
+			pending:  pending("BISTRO", "$40.00", 18),
+			posted:   posted("CAFE", "$40.00", 18),
+			expected: []ledger.Transaction{posted("CAFE", "$40.00", 18)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.conf.StateFile = filepath.Join(t.TempDir(), "state.json")
+			tc.conf.Now = now
+			r, err := New(tc.conf)
+			assert.NoError(t, err)
+			out, err := r.Process(tc.pending)
+			assert.NoError(t, err)
+			assert.Equal(t, []ledger.Transaction{tc.pending}, out)
+
+			// the pending entry survives a restart
+			r, err = New(tc.conf)
+			assert.NoError(t, err)
+			out, err = r.Process(tc.posted)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, out)
+		})
+	}
+}
+
+func TestProcessMatchesOnce(t *testing.T) {
+	r, err := New(Config{StateFile: filepath.Join(t.TempDir(), "state.json"), Now: now})
+	assert.NoError(t, err)
+	_, err = r.Process(pending("BISTRO", "$40.00", 18))
+	assert.NoError(t, err)
+	out, err := r.Process(posted("BISTRO", "$40.00", 19))
+	assert.NoError(t, err)
+	assert.Nil(t, out)
+	out, err = r.Process(posted("BISTRO", "$40.00", 19))
+	assert.NoError(t, err)
+	assert.Equal(t, []ledger.Transaction{posted("BISTRO", "$40.00", 19)}, out)
+}
+
+func TestProcessExpiresAtRunTime(t *testing.T) {
+	conf := Config{StateFile: filepath.Join(t.TempDir(), "state.json"), Now: now}
+	r, err := New(conf)
+	assert.NoError(t, err)
+	_, err = r.Process(pending("BISTRO", "$40.00", 18))
+	assert.NoError(t, err)
+	_, err = r.Process(pending("DINER", "$12.00", 24))
+	assert.NoError(t, err)
+
+	// a line dated ahead of the others, read out of order, doesn't expire
+	// entries that are still valid
+	ahead := posted("GROCER", "$30.00", 1)
+	ahead.Date = ahead.Date.AddDate(0, 2, 0)
+	out, err := r.Process(ahead)
+	assert.NoError(t, err)
+	assert.Equal(t, []ledger.Transaction{ahead}, out)
+	out, err = r.Process(posted("BISTRO", "$40.00", 19))
+	assert.NoError(t, err)
+	assert.Nil(t, out)
+
+	// a later run drops what is older than the expiry
+	conf.Now = now.AddDate(0, 0, 30)
+	r, err = New(conf)
+	assert.NoError(t, err)
+	out, err = r.Process(posted("DINER", "$12.00", 25))
+	assert.NoError(t, err)
+	assert.Equal(t, []ledger.Transaction{posted("DINER", "$12.00", 25)}, out)
+}
@@ -0,0 +1,120 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+)
+
+// CSV reads statements exported as CSV. The header row names the columns:
+// the date, the description and either a signed amount or separate debit
+// and credit columns, with an optional reference. Rows before the header,
+// such as account details, are skipped.
+type CSV struct{}
+
+// columns lists the header names of each column, most preferred first, e.g.
+// the transaction date before the date it posted.
+var columns = map[string][]string{
+	"date":   {"transaction date", "trans. date", "trans date", "date", "posting date", "post date", "posted date"},
+	"payee":  {"description", "payee", "merchant", "name", "details", "memo"},
+	"amount": {"amount", "amount (usd)", "transaction amount"},
+	"debit":  {"debit", "withdrawal", "withdrawals", "withdrawal amount", "debit amount"},
+	"credit": {"credit", "deposit", "deposits", "deposit amount", "credit amount"},
+	"id":     {"reference", "reference number", "ref", "transaction id", "check number", "check or slip #"},
+}
+
+func (CSV) Match(a email.Attachment) bool {
+	return a.MimeType == "text/csv" || a.MimeType == "application/csv" || strings.EqualFold(path.Ext(a.Filename), ".csv")
+}
+
+func (CSV) Parse(a email.Attachment, sent time.Time) ([]Line, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(a.Data, []byte("\ufeff"))))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var col map[string]int
+	var lines []Line
+	for _, rec := range records {
+		if col == nil {
+			col = header(rec)
+			continue
+		}
+		get := func(name string) string {
+			i, ok := col[name]
+			if !ok || i >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[i])
+		}
+		if get("date") == "" {
+			continue
+		}
+		d, err := date(get("date"), sent)
+		if err != nil {
+			return nil, err
+		}
+		l := Line{Date: d, Payee: get("payee"), ID: get("id")}
+		switch {
+		case get("amount") != "":
+			l.Amount, err = amount(get("amount"))
+		case !zero(get("debit")):
+			l.Amount, err = amount(get("debit"))
+			l.Amount = "-" + strings.TrimPrefix(l.Amount, "-")
+		case !zero(get("credit")):
+			l.Amount, err = amount(get("credit"))
+			l.Amount = strings.TrimPrefix(l.Amount, "-")
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	if col == nil {
+		return nil, errors.New("no header row")
+	}
+	return lines, nil
+}
+
+// zero reports whether a debit or credit column is unused: empty, or a zero
+// amount as some banks write in the column that doesn't apply.
+func zero(s string) bool {
+	if s == "" {
+		return true
+	}
+	a, err := amount(s)
+	return err == nil && strings.Trim(a, "-$0.,") == ""
+}
+
+// header returns the index of each known column of rec, or nil when rec
+// isn't a header row with a date and amounts.
+func header(rec []string) map[string]int {
+	names := make([]string, len(rec))
+	for i, n := range rec {
+		names[i] = strings.ToLower(strings.TrimSpace(n))
+	}
+	col := map[string]int{}
+	for name, headers := range columns {
+		for _, h := range headers {
+			if i := slices.Index(names, h); i >= 0 {
+				col[name] = i
+				break
+			}
+		}
+	}
+	_, amount := col["amount"]
+	_, debit := col["debit"]
+	if _, ok := col["date"]; !ok || !amount && !debit {
+		return nil
+	}
+	return col
+}
@@ -0,0 +1,331 @@
+package statement
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+)
+
+// PDF reads statements from the text of PDF files, a line per transaction
+// starting with its date and ending with its amount, optionally followed by
+// the balance: "01/15 01/16 AMAZON MKTPLACE -12.34 1,022.50". Dates without
+// a year are read as before the statement's closing date. Text is taken
+// from the content streams as written, so scanned statements, and those
+// whose fonts don't map to ASCII, read no lines.
+type PDF struct{}
+
+const (
+	pdfDate   = `\d{1,2}/\d{1,2}(?:/\d{2,4})?`
+	pdfAmount = `\(?[+-]?\$?-?(?:\d{1,3}(?:,\d{3})*|\d+)\.\d{2}\)?`
+)
+
+var (
+	expPDFLine = regexp.MustCompile(`^(?P<date>` + pdfDate + `)\s+(?:` + pdfDate + `\s+)?(?P<payee>.*?\S)\s+(?P<amt>` + pdfAmount + `(?:-|\s?CR)?)(?:\s+` + pdfAmount + `)?$`)
+	// expClosing matches the end of the statement period, the second date
+	// of "Opening/Closing Date 01/01/25 - 01/31/25"
+	expClosing = regexp.MustCompile(`(?i)(?:closing date|statement date|statement period|period ending|through)\s*:?\s*(\d{1,2}/\d{1,2}/\d{2,4})(?:\s*(?:-|to)\s*(\d{1,2}/\d{1,2}/\d{2,4}))?`)
+	expSpaces  = regexp.MustCompile(`[ \t]+`)
+)
+
+func (PDF) Match(a email.Attachment) bool {
+	return a.MimeType == "application/pdf" || strings.EqualFold(path.Ext(a.Filename), ".pdf")
+}
+
+func (PDF) Parse(a email.Attachment, sent time.Time) ([]Line, error) {
+	text := pdfText(a.Data)
+	ref := sent
+	if m := expClosing.FindStringSubmatch(text); m != nil {
+		closing := m[1]
+		if m[2] != "" {
+			closing = m[2]
+		}
+		if d, err := date(closing, sent); err == nil {
+			ref = d
+		}
+	}
+	var lines []Line
+	for _, s := range strings.Split(text, "\n") {
+		m := expPDFLine.FindStringSubmatch(strings.TrimSpace(s))
+		if m == nil {
+			continue
+		}
+		d, err := date(m[1], ref)
+		if err != nil {
+			return nil, err
+		}
+		amt, err := amount(m[3])
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, Line{Date: d, Payee: m[2], Amount: amt})
+	}
+	return lines, nil
+}
+
+// pdfText returns the text shown by the content streams of a PDF, a line
+// per line of text on the page. Streams that aren't uncompressed or
+// deflated, such as images, are skipped.
+func pdfText(data []byte) string {
+	var b strings.Builder
+	for {
+		i := bytes.Index(data, []byte("stream"))
+		if i < 0 {
+			break
+		}
+		dict, rest := data[:i], data[i+len("stream"):]
+		if bytes.HasSuffix(dict, []byte("end")) {
+			data = rest
+			continue
+		}
+		rest = bytes.TrimPrefix(bytes.TrimPrefix(rest, []byte("\r")), []byte("\n"))
+		end := bytes.Index(rest, []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		content := rest[:end]
+		data = rest[end+len("endstream"):]
+		if o := bytes.LastIndex(dict, []byte("obj")); o >= 0 {
+			dict = dict[o:]
+		}
+		switch {
+		case bytes.Contains(dict, []byte("/FlateDecode")):
+			r, err := zlib.NewReader(bytes.NewReader(content))
+			if err != nil {
+				continue
+			}
+			// statements may pad streams after the compressed data
+			content, _ = io.ReadAll(r)
+		case bytes.Contains(dict, []byte("/Filter")):
+			continue
+		}
+		b.WriteString(contentText(content))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// contentText returns the text shown by the operators of a content stream,
+// starting a new line whenever the text moves to another line and
+// separating the pieces of text on a line with spaces.
+func contentText(content []byte) string {
+	var b strings.Builder
+	var operands []any
+	// y is the vertical position of the text, and shownY that of the last
+	// text shown
+	var y, shownY float64
+	line := false
+	show := func(s string) {
+		if line && y != shownY {
+			b.WriteString("\n")
+		} else if line {
+			b.WriteString(" ")
+		}
+		b.WriteString(s)
+		line, shownY = true, y
+	}
+	// last returns the last operand, which is all the text operators use
+	last := func() any {
+		if len(operands) == 0 {
+			return nil
+		}
+		return operands[len(operands)-1]
+	}
+	lex := lexer{data: content}
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			break
+		}
+		op, isOp := tok.(operator)
+		if !isOp {
+			operands = append(operands, tok)
+			continue
+		}
+		switch op {
+		case "Tj":
+			s, _ := last().(string)
+			show(s)
+		case "'", "\"":
+			y--
+			s, _ := last().(string)
+			show(s)
+		case "TJ":
+			var s strings.Builder
+			arr, _ := last().([]any)
+			for _, e := range arr {
+				switch e := e.(type) {
+				case string:
+					s.WriteString(e)
+				case float64:
+					// a wide negative kerning is a space between words
+					if e < -200 {
+						s.WriteString(" ")
+					}
+				}
+			}
+			show(s.String())
+		case "Td", "TD":
+			f, _ := last().(float64)
+			y += f
+		case "Tm":
+			f, _ := last().(float64)
+			y = f
+		case "T*":
+			y--
+		case "BT":
+			y = 0
+		}
+		operands = operands[:0]
+	}
+	var lines []string
+	for _, l := range strings.Split(b.String(), "\n") {
+		lines = append(lines, strings.TrimSpace(expSpaces.ReplaceAllString(l, " ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// operator is a content stream operator, such as Tj.
+type operator string
+
+// lexer reads the tokens of a content stream: numbers as float64, strings
+// as string, arrays as []any and everything else, including names, as
+// operator.
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func (l *lexer) next() (any, bool) {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		case isSpace(c):
+			l.pos++
+		case c == '(':
+			return l.literal(), true
+		case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<', c == '>' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '>':
+			l.pos += 2
+			return operator(string(l.data[l.pos-2 : l.pos])), true
+		case c == '<':
+			return l.hex(), true
+		case c == '[':
+			l.pos++
+			var arr []any
+			for {
+				tok, ok := l.next()
+				if !ok || tok == operator("]") {
+					return arr, true
+				}
+				arr = append(arr, tok)
+			}
+		case c == ']':
+			l.pos++
+			return operator("]"), true
+		default:
+			start := l.pos
+			l.pos++
+			for l.pos < len(l.data) && !isSpace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+				l.pos++
+			}
+			word := string(l.data[start:l.pos])
+			if f, err := strconv.ParseFloat(word, 64); err == nil {
+				return f, true
+			}
+			return operator(word), true
+		}
+	}
+	return nil, false
+}
+
+// literal reads a string in parentheses, which may nest.
+func (l *lexer) literal() string {
+	var b strings.Builder
+	depth := 0
+	for l.pos++; l.pos < len(l.data); l.pos++ {
+		c := l.data[l.pos]
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				l.pos++
+				return b.String()
+			}
+			depth--
+		case '\\':
+			l.pos++
+			if l.pos >= len(l.data) {
+				return b.String()
+			}
+			c = l.data[l.pos]
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				// a line continuation
+				continue
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				n := 0
+				for i := 0; i < 3 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+					n = n*8 + int(l.data[l.pos]-'0')
+					l.pos++
+				}
+				l.pos--
+				c = byte(n)
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// hex reads a string written in hexadecimal between angle brackets.
+func (l *lexer) hex() string {
+	end := bytes.IndexByte(l.data[l.pos:], '>')
+	if end < 0 {
+		end = len(l.data) - l.pos
+	}
+	digits := strings.Map(func(r rune) rune {
+		if strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return r
+		}
+		return -1
+	}, string(l.data[l.pos+1:l.pos+end]))
+	l.pos += end + 1
+	if len(digits)%2 == 1 {
+		digits += "0"
+	}
+	var b strings.Builder
+	for i := 0; i < len(digits); i += 2 {
+		n, _ := strconv.ParseUint(digits[i:i+2], 16, 8)
+		b.WriteByte(byte(n))
+	}
+	return b.String()
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == 0
+}
+
+func isDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
@@ -0,0 +1,117 @@
+// Package statement reads the transactions of monthly statements attached
+// to emails, such as CSV exports and PDF statements.
+package statement
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+)
+
+// Line is a transaction listed in a statement. Amount is signed as in the
+// statement, money into the account positive unless the statement writes
+// charges positive, as card statements do.
+type Line struct {
+	Date   time.Time
+	Payee  string
+	Amount string
+	ID     string
+}
+
+// Parser reads the lines of statements in one format.
+type Parser interface {
+	// Match reports whether a is a statement the parser reads.
+	Match(a email.Attachment) bool
+	// Parse returns the lines of statement a, which came in an email sent
+	// at sent, for dates written without a year.
+	Parse(a email.Attachment, sent time.Time) ([]Line, error)
+}
+
+// Parsers are the formats statements are read in by default.
+var Parsers = []Parser{CSV{}, PDF{}}
+
+// Parse returns the lines of every attachment read by one of parsers, and
+// false when none of them reads any attachment.
+func Parse(as []email.Attachment, sent time.Time, parsers []Parser) ([]Line, bool, error) {
+	var lines []Line
+	var ok bool
+	for _, a := range as {
+		for _, p := range parsers {
+			if !p.Match(a) {
+				continue
+			}
+			ls, err := p.Parse(a, sent)
+			if err != nil {
+				return nil, false, fmt.Errorf("statement %s: %w", a.Filename, err)
+			}
+			lines, ok = append(lines, ls...), true
+			break
+		}
+	}
+	return lines, ok, nil
+}
+
+// Transaction books the line as cleared in account, money received when its
+// amount is positive. Invert reads charges as positive amounts instead.
+func (l Line) Transaction(account string, invert bool) (ledger.Transaction, error) {
+	t := ledger.Transaction{
+		ID:      l.ID,
+		Status:  ledger.Cleared,
+		Payee:   l.Payee,
+		Date:    l.Date,
+		Account: account,
+	}
+	a, err := ledger.ParseAmount(l.Amount)
+	if err != nil {
+		return t, err
+	}
+	if invert {
+		a = a.Neg()
+	}
+	t.IsReceive = a.Quantity > 0
+	t.Amount = a.Abs().String()
+	return t, nil
+}
+
+var (
+	// expNumber matches the amounts of statements: "-1,234.56", "$12.34",
+	// "(12.34)", "12.34-" or "12.34 CR".
+	expNumber = regexp.MustCompile(`^(\()?([+-])?\$?([+-])?(\d{1,3}(?:,\d{3})*|\d+)\.(\d{2})(\))?(-|\s*CR)?$`)
+	// dateLayouts are the layouts of statement dates with a year.
+	dateLayouts = []string{"01/02/2006", "1/2/2006", "2006-01-02", "01/02/06", "1/2/06", "Jan 2, 2006", "January 2, 2006", "02 Jan 2006", "2 Jan 2006"}
+)
+
+// amount reads a statement amount as a ledger amount in dollars.
+func amount(s string) (string, error) {
+	m := expNumber.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return "", fmt.Errorf("invalid amount %q", s)
+	}
+	neg := m[1] != "" || m[2] == "-" || m[3] == "-" || m[7] != ""
+	a := "$" + m[4] + "." + m[5]
+	if neg {
+		a = "-" + a
+	}
+	return a, nil
+}
+
+// date reads a statement date, giving dates without a year the one that
+// makes them the closest date not after ref.
+func date(s string, ref time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if d, err := time.ParseInLocation(layout, s, ref.Location()); err == nil {
+			return d, nil
+		}
+	}
+	for _, layout := range []string{"01/02", "1/2", "Jan 2"} {
+		if d, err := email.PartialDate(layout, s, ref); err == nil {
+			return d, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
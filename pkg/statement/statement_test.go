@@ -0,0 +1,142 @@
+package statement
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/stretchr/testify/assert"
+)
+
+var sent = time.Date(2025, 2, 3, 9, 0, 0, 0, time.UTC)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Line
+	}{
+		{
+			name: "signed amounts",
+			data: "\ufeffTransaction Date,Post Date,Description,Category,Type,Amount,Memo\n" +
+				"01/15/2025,01/16/2025,AMAZON MKTPLACE,Shopping,Sale,-12.34,\n" +
+				"01/20/2025,01/20/2025,\"PAYMENT, THANK YOU\",,Payment,\"1,000.00\",\n",
+			want: []Line{
+				{Date: day(2025, 1, 15), Payee: "AMAZON MKTPLACE", Amount: "-$12.34"},
+				{Date: day(2025, 1, 20), Payee: "PAYMENT, THANK YOU", Amount: "$1,000.00"},
+			},
+		},
+		{
+			name: "debit and credit columns after account details",
+			data: "Account,Checking ...4321\n\n" +
+				"Date,Description,Withdrawals,Deposits,Balance,Reference\n" +
+				"2025-01-02,CITY UTILITIES,$45.00,,955.00,R1\n" +
+				"2025-01-03,ACME PAYROLL,,\"$2,345.67\",3300.67,R2\n" +
+				"2025-01-04,INTEREST,0.00,1.25,3301.92,R3\n",
+			want: []Line{
+				{Date: day(2025, 1, 2), Payee: "CITY UTILITIES", Amount: "-$45.00", ID: "R1"},
+				{Date: day(2025, 1, 3), Payee: "ACME PAYROLL", Amount: "$2,345.67", ID: "R2"},
+				{Date: day(2025, 1, 4), Payee: "INTEREST", Amount: "$1.25", ID: "R3"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := email.Attachment{Filename: "statement.CSV", Data: []byte(tt.data)}
+			assert.True(t, CSV{}.Match(a))
+			got, err := CSV{}.Parse(a, sent)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCSVNoHeader(t *testing.T) {
+	_, err := CSV{}.Parse(email.Attachment{Data: []byte("a,b\n1,2\n")}, sent)
+	assert.Error(t, err)
+}
+
+// pdf returns a PDF whose page shows content, deflated when compress is set.
+func pdf(content string, compress bool) []byte {
+	stream, filter := []byte(content), ""
+	if compress {
+		var b bytes.Buffer
+		w := zlib.NewWriter(&b)
+		w.Write(stream)
+		w.Close()
+		stream, filter = b.Bytes(), " /Filter /FlateDecode"
+	}
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n")
+	b.WriteString("2 0 obj << /Type /Pages /Kids [3 0 R] /Count 1 >> endobj\n")
+	b.WriteString("3 0 obj << /Type /Page /Parent 2 0 R /Contents 4 0 R >> endobj\n")
+	fmt.Fprintf(&b, "4 0 obj << /Length %d%s >>\nstream\n", len(stream), filter)
+	b.Write(stream)
+	b.WriteString("\nendstream\nendobj\ntrailer << /Root 1 0 R >>\n%%EOF\n")
+	return b.Bytes()
+}
+
+const pdfContent = `BT /F1 10 Tf 50 760 Td (Opening/Closing Date 12/05/24 - 01/04/25) Tj ET
+BT /F1 10 Tf 1 0 0 1 50 700 Tm (12/28) Tj ET
+BT /F1 10 Tf 1 0 0 1 100 700 Tm [(AMAZON) -300 (MKTPLACE)] TJ ET
+BT /F1 10 Tf 1 0 0 1 400 700 Tm (12.34) Tj ET
+BT /F1 10 Tf 1 0 0 1 50 688 Tm (01/02 PAYMENT THANK YOU \(ONLINE\)) Tj ET
+BT /F1 10 Tf 1 0 0 1 400 688 Tm (-500.00) Tj ET
+BT /F1 10 Tf 50 600 Td (Total fees charged in 2025) Tj 0 -12 Td <24302e3030> Tj ET`
+
+func TestPDF(t *testing.T) {
+	want := []Line{
+		{Date: day(2024, 12, 28), Payee: "AMAZON MKTPLACE", Amount: "$12.34"},
+		{Date: day(2025, 1, 2), Payee: "PAYMENT THANK YOU (ONLINE)", Amount: "-$500.00"},
+	}
+	for _, compress := range []bool{false, true} {
+		t.Run(fmt.Sprint("compressed ", compress), func(t *testing.T) {
+			a := email.Attachment{Filename: "statement.pdf", MimeType: "application/pdf", Data: pdf(pdfContent, compress)}
+			assert.True(t, PDF{}.Match(a))
+			got, err := PDF{}.Parse(a, sent)
+			assert.NoError(t, err)
+			// dates without a year carry the zone PartialDate gives them
+			for i := range got {
+				got[i].Date = got[i].Date.UTC()
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestAmount(t *testing.T) {
+	for in, want := range map[string]string{
+		"12.34":     "$12.34",
+		"-1,234.56": "-$1,234.56",
+		"$-3.10":    "-$3.10",
+		"(45.00)":   "-$45.00",
+		"45.00-":    "-$45.00",
+		"45.00 CR":  "-$45.00",
+		"+8.00":     "$8.00",
+	} {
+		got, err := amount(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	_, err := amount("12")
+	assert.Error(t, err)
+}
+
+func TestLineTransaction(t *testing.T) {
+	l := Line{Date: day(2025, 1, 15), Payee: "AMAZON MKTPLACE", Amount: "$12.34"}
+	tx, err := l.Transaction("liabilities:card", true)
+	assert.NoError(t, err)
+	assert.False(t, tx.IsReceive)
+	assert.Equal(t, "$12.34", tx.Amount)
+
+	tx, err = l.Transaction("assets:checking", false)
+	assert.NoError(t, err)
+	assert.True(t, tx.IsReceive)
+}
@@ -0,0 +1,96 @@
+// Package trade turns brokerage and crypto trade confirmations into lots
+// bought or sold at their cost, e.g. "10 VTI @ $220.00".
+package trade
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+)
+
+// DefaultFeeAccount receives commissions and other trading fees.
+const DefaultFeeAccount = "expenses:fees:trading"
+
+// Trade is an executed order. Quantity and Symbol make the lot, e.g. "10"
+// and "VTI". Price is the cost of one unit; confirmations that only give
+// the value of the whole order set Value instead. Fee is charged on top of
+// a purchase and taken out of the proceeds of a sale.
+type Trade struct {
+	Sell     bool
+	Symbol   string
+	Quantity string
+	Price    string
+	Value    string
+	Fee      string
+	Date     time.Time
+	ID       string
+	Broker   string
+}
+
+// Accounts are where the parts of a trade are posted. Holdings is the
+// parent of the account of each symbol, e.g. "assets:fidelity" holds
+// "assets:fidelity:VTI"; Cash pays for purchases and receives the proceeds
+// of sales.
+type Accounts struct {
+	Holdings string
+	Cash     string
+	Fee      string
+}
+
+// Transaction books the lot to the symbol's account at its cost, the fee to
+// the fee account, and the cash side to what balances them.
+func (tr Trade) Transaction(accounts Accounts) (ledger.Transaction, error) {
+	if accounts.Fee == "" {
+		accounts.Fee = DefaultFeeAccount
+	}
+	if accounts.Cash == "" {
+		accounts.Cash = accounts.Holdings
+	}
+	side := "Buy"
+	if tr.Sell {
+		side = "Sell"
+	}
+	t := ledger.Transaction{
+		ID: tr.ID,
+		// confirmations are sent once the order is executed
+		Status:    ledger.Cleared,
+		Payee:     strings.TrimSpace(fmt.Sprintf("%s %s %s", tr.Broker, side, tr.Symbol)),
+		Date:      tr.Date,
+		Account:   accounts.Cash,
+		IsReceive: tr.Sell,
+	}
+	qty, err := ledger.ParseAmount(tr.Quantity)
+	if err != nil {
+		return t, err
+	}
+	qty.Commodity = tr.Symbol
+	if tr.Sell {
+		qty = qty.Neg()
+	}
+	lot := ledger.Posting{Account: accounts.Holdings + ":" + tr.Symbol, Amount: qty.String(), Cost: tr.Price}
+	if tr.Price == "" {
+		lot.Cost, lot.TotalCost = tr.Value, true
+	}
+	if lot.Cost == "" {
+		return t, fmt.Errorf("trade of %s has no price", qty)
+	}
+	t.Postings = []ledger.Posting{lot}
+	if tr.Fee != "" {
+		t.Postings = append(t.Postings, ledger.Posting{Account: accounts.Fee, Amount: tr.Fee})
+	}
+	t.Postings = append(t.Postings, ledger.Posting{Account: accounts.Cash})
+	if err := t.AutoBalance(); err != nil {
+		return t, err
+	}
+	cash, err := ledger.ParseAmount(t.Postings[len(t.Postings)-1].Amount)
+	if err != nil {
+		return t, err
+	}
+	// cash settles in cents whatever the precision of the price
+	cash = cash.Round(2)
+	t.Postings[len(t.Postings)-1].Amount = cash.String()
+	t.Amount = cash.Abs().String()
+	return t, nil
+}
@@ -0,0 +1,69 @@
+package trade
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction(t *testing.T) {
+	date := time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC)
+	accounts := Accounts{Holdings: "assets:brokerage", Cash: "assets:brokerage:cash"}
+	testCases := []struct {
+		name     string
+		trade    Trade
+		expected ledger.Transaction
+	}{
+		{
+			name:  "buy at a unit price",
+			trade: Trade{Broker: "Fidelity", Symbol: "VTI", Quantity: "10", Price: "$220.00", Fee: "$1.00"},
+			expected: ledger.Transaction{
+				Payee: "Fidelity Buy VTI", Amount: "$2201.00",
+				Postings: []ledger.Posting{
+					{Account: "assets:brokerage:VTI", Amount: "10 VTI", Cost: "$220.00"},
+					{Account: DefaultFeeAccount, Amount: "$1.00"},
+					{Account: "assets:brokerage:cash", Amount: "-$2201.00"},
+				},
+			},
+		},
+		{
+			name:  "sell a ticker with punctuation",
+			trade: Trade{Broker: "Schwab", Sell: true, Symbol: "BRK.B", Quantity: "2", Price: "$451.23"},
+			expected: ledger.Transaction{
+				Payee: "Schwab Sell BRK.B", Amount: "$902.46", IsReceive: true,
+				Postings: []ledger.Posting{
+					{Account: "assets:brokerage:BRK.B", Amount: `-2 "BRK.B"`, Cost: "$451.23"},
+					{Account: "assets:brokerage:cash", Amount: "$902.46"},
+				},
+			},
+		},
+		{
+			name:  "buy at a total value",
+			trade: Trade{Broker: "Coinbase", Symbol: "BTC", Quantity: "0.01234567", Value: "$492.63", Fee: "$7.37"},
+			expected: ledger.Transaction{
+				Payee: "Coinbase Buy BTC", Amount: "$500.00",
+				Postings: []ledger.Posting{
+					{Account: "assets:brokerage:BTC", Amount: "0.01234567 BTC", Cost: "$492.63", TotalCost: true},
+					{Account: DefaultFeeAccount, Amount: "$7.37"},
+					{Account: "assets:brokerage:cash", Amount: "-$500.00"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.trade.Date = date
+			tx, err := tc.trade.Transaction(accounts)
+			assert.NoError(t, err)
+			tc.expected.Status, tc.expected.Date, tc.expected.Account = ledger.Cleared, date, "assets:brokerage:cash"
+			assert.Equal(t, tc.expected, tx)
+			assert.NoError(t, tx.Check())
+		})
+	}
+
+	_, err := Trade{Symbol: "VTI", Quantity: "1"}.Transaction(accounts)
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package adp
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/payroll"
+	"google.golang.org/api/gmail/v1"
+)
+
+var layout = payroll.Layout{
+	Gross:  []string{"Gross Pay", "Gross Earnings", "Total Gross"},
+	Net:    []string{"Net Pay", "Net Pay Distribution", "Take Home Pay"},
+	Ignore: []string{"Total Taxes", "Total Deductions", "Regular Hours", "Rate"},
+}
+
+// ProviderADP reads ADP pay statement emails. Account is where the net pay
+// is deposited.
+type ProviderADP struct {
+	Account  string
+	Accounts payroll.Accounts
+}
+
+func (p *ProviderADP) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	body, err := email.HTML(msg)
+	if err != nil || body == nil {
+		return nil, err
+	}
+	fields, err := email.TableFields(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	s, ok := payroll.Parse(fields, layout)
+	if !ok {
+		return nil, nil
+	}
+	s.Employer = fields.Get("Company", "Employer", "Company Name")
+	if s.Employer == "" {
+		s.Employer = "ADP payroll"
+	}
+	s.Date = email.Sent(msg)
+	if d, err := time.Parse("01/02/2006", fields.Get("Pay Date", "Check Date")); err == nil {
+		s.Date = d
+	}
+	if s.Date.IsZero() {
+		return nil, errors.New("adp: email has no date")
+	}
+	t, err := s.Transaction(p.Account, p.Accounts)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (p *ProviderADP) GetAccount() string {
+	return p.Account
+}
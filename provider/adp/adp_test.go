@@ -0,0 +1,41 @@
+package adp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/payroll"
+	"github.com/stretchr/testify/assert"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Thu, 1 May 2025 09:00:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	p := &ProviderADP{Account: "assets:checking", Accounts: payroll.Accounts{Deduction: "expenses:benefits"}}
+	tx, err := p.GetTransaction(emailtest.HTML(t, dateHeader, "Your pay statement is ready", "statement.html"))
+	assert.NoError(t, err)
+	assert.Equal(t, &ledger.Transaction{
+		Status:    ledger.Cleared,
+		Payee:     "ACME CORP",
+		Amount:    "$2,654.25",
+		Date:      time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC),
+		Account:   "assets:checking",
+		IsReceive: true,
+		Postings: []ledger.Posting{
+			{Account: "assets:checking", Amount: "$2,654.25"},
+			{Account: payroll.DefaultIncomeAccount, Amount: "-$4000.00"},
+			{Account: payroll.DefaultTaxAccount, Amount: "$480.00", Comment: "Federal Income Tax"},
+			{Account: payroll.DefaultTaxAccount, Amount: "$248.00", Comment: "Social Security Tax"},
+			{Account: payroll.DefaultTaxAccount, Amount: "$58.00", Comment: "Medicare Tax"},
+			{Account: payroll.DefaultTaxAccount, Amount: "$190.50", Comment: "CA State Income Tax"},
+			{Account: payroll.DefaultTaxAccount, Amount: "$44.00", Comment: "CA SDI"},
+			{Account: "expenses:benefits", Amount: "$240.00", Comment: "401(k)"},
+			{Account: "expenses:benefits", Amount: "$85.25", Comment: "Medical"},
+		},
+	}, tx)
+	assert.NoError(t, tx.Check())
+
+}
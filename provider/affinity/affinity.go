@@ -4,16 +4,19 @@ import (
 	"regexp"
 	"time"
 
+	"github.com/mikelu92/emailimport/pkg/email"
 	"github.com/mikelu92/emailimport/pkg/ledger"
 	"google.golang.org/api/gmail/v1"
 )
 
 var (
-	exp *regexp.Regexp
+	exp       *regexp.Regexp
+	expCredit *regexp.Regexp
 )
 
 func init() {
 	exp, _ = regexp.Compile("Service Charge for (?P<amt>\\$\\d+\\.\\d+) on (?P<date>.*) at (?P<payee>.*) on card ending in")
+	expCredit, _ = regexp.Compile("(?:Reversal of Service Charge|Credit|Refund|Return) for (?P<amt>\\$\\d+\\.\\d+) on (?P<date>.*) at (?P<payee>.*) on card ending in")
 }
 
 type ProviderAffinity struct {
@@ -21,8 +24,15 @@ type ProviderAffinity struct {
 }
 
 func (p *ProviderAffinity) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
-	t := ledger.Transaction{}
-	match := exp.FindStringSubmatch(msg.Snippet)
+	t := ledger.Transaction{Account: p.Account}
+	exp := exp
+	match := expCredit.FindStringSubmatch(msg.Snippet)
+	if len(match) != 0 {
+		exp = expCredit
+		t.IsReceive = true
+	} else {
+		match = exp.FindStringSubmatch(msg.Snippet)
+	}
 	if len(match) == 0 {
 		return nil, nil
 	}
@@ -33,15 +43,23 @@ func (p *ProviderAffinity) GetTransaction(msg *gmail.Message) (*ledger.Transacti
 			result[name] = match[i]
 		}
 	}
-	d, err := time.Parse("01/02 15:04 MST", result["date"])
+	// the alert omits the year, take the one of the email
+	sent := email.Sent(msg)
+	if sent.IsZero() {
+		sent = time.Now()
+	}
+	d, err := email.PartialDate("01/02 15:04 MST", result["date"], sent)
 	if err != nil {
 		return nil, err
 	}
-	d = d.AddDate(time.Now().Year(), 0, 0)
 
 	t.Payee = result["payee"]
 	t.Amount = result["amt"]
 	t.Date = d
+	t.Status = ledger.Pending
+	if t.IsReceive {
+		t.Status = ledger.Cleared
+	}
 	return &t, nil
 }
 
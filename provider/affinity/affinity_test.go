@@ -0,0 +1,64 @@
+package affinity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestGetTransaction(t *testing.T) {
+	tests := []struct {
+		name    string
+		snippet string
+		date    string
+		want    time.Time
+		receive bool
+	}{
+		{
+			name:    "same year",
+			snippet: "Service Charge for $12.34 on 08/16 18:05 CDT at CORNER CAFE on card ending in 1234",
+			date:    "Sat, 16 Aug 2025 23:10:02 +0000",
+			want:    time.Date(2025, 8, 16, 23, 5, 0, 0, time.UTC),
+		},
+		{
+			name:    "december charge imported in january",
+			snippet: "Service Charge for $45.00 on 12/31 23:10 EST at NEW YEARS MARKET on card ending in 1234",
+			date:    "Thu, 1 Jan 2026 05:00:00 +0000",
+			want:    time.Date(2026, 1, 1, 4, 10, 0, 0, time.UTC),
+		},
+		{
+			name:    "refund",
+			snippet: "Refund for $9.99 on 01/02 09:00 PST at BOOK SHOP on card ending in 1234",
+			date:    "Fri, 2 Jan 2026 17:30:00 +0000",
+			want:    time.Date(2026, 1, 2, 17, 0, 0, 0, time.UTC),
+			receive: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &gmail.Message{
+				Snippet: tt.snippet,
+				Payload: &gmail.MessagePart{Headers: []*gmail.MessagePartHeader{{Name: "Date", Value: tt.date}}},
+			}
+			p := &ProviderAffinity{Account: "assets:affinity"}
+			tx, err := p.GetTransaction(msg)
+			if err != nil {
+				t.Fatalf("GetTransaction returned error: %v", err)
+			}
+			if tx == nil {
+				t.Fatalf("expected transaction, got nil")
+			}
+			if !tx.Date.Equal(tt.want) {
+				t.Fatalf("expected date %v, got %v", tt.want, tx.Date.UTC())
+			}
+			if tx.IsReceive != tt.receive {
+				t.Fatalf("expected IsReceive %v", tt.receive)
+			}
+			if tt.receive && tx.Status != ledger.Cleared {
+				t.Fatalf("expected cleared refund, got %q", tx.Status)
+			}
+		})
+	}
+}
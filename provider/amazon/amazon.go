@@ -0,0 +1,65 @@
+package amazon
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/receipt"
+	"google.golang.org/api/gmail/v1"
+)
+
+var (
+	layout = receipt.Layout{
+		Subtotal: []string{"Item Subtotal", "Items", "Total before tax"},
+		Fees:     []string{"Shipping & Handling", "Shipping", "Delivery"},
+		Discount: []string{"Promotion Applied", "Your Coupon Savings", "Subscribe & Save", "Discount"},
+		Tax:      []string{"Estimated tax to be collected", "Estimated tax", "Tax"},
+		Total:    []string{"Order Total", "Grand Total", "Total"},
+		Ignore:   []string{"Gift Card Amount", "Rewards Points"},
+	}
+	expOrder = regexp.MustCompile(`\b\d{3}-\d{7}-\d{7}\b`)
+)
+
+// ProviderAmazon reads Amazon order confirmations. Account is the card or
+// account orders are paid with.
+type ProviderAmazon struct {
+	Account  string
+	Accounts receipt.Accounts
+}
+
+func (p *ProviderAmazon) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	body, err := email.HTML(msg)
+	if err != nil || body == nil {
+		return nil, err
+	}
+	fields, err := email.TableFields(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r, ok := receipt.Parse(fields, layout)
+	if !ok {
+		return nil, nil
+	}
+	r.Merchant = "Amazon"
+	r.Order = expOrder.FindString(email.Text(msg))
+	r.Date = email.Sent(msg)
+	if d, err := time.Parse("January 2, 2006", fields.Get("Order Placed", "Ordered on", "Order date")); err == nil {
+		r.Date = d
+	}
+	if r.Date.IsZero() {
+		return nil, errors.New("amazon: email has no date")
+	}
+	t, err := r.Transaction(p.Account, p.Accounts)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (p *ProviderAmazon) GetAccount() string {
+	return p.Account
+}
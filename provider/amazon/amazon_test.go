@@ -0,0 +1,37 @@
+package amazon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/receipt"
+	"github.com/stretchr/testify/assert"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Mon, 5 May 2025 08:15:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	p := &ProviderAmazon{Account: "liabilities:chase"}
+	tx, err := p.GetTransaction(emailtest.HTML(t, dateHeader, "Your Amazon.com order #112-4433221-7788990", "order.html"))
+	assert.NoError(t, err)
+	assert.Equal(t, &ledger.Transaction{
+		ID:      "112-4433221-7788990",
+		Status:  ledger.Cleared,
+		Payee:   "Amazon",
+		Amount:  "$64.77",
+		Date:    time.Date(2025, 5, 4, 0, 0, 0, 0, time.UTC),
+		Account: "liabilities:chase",
+		Postings: []ledger.Posting{
+			{Account: "liabilities:chase", Amount: "-$64.77"},
+			{Account: ledger.Uncategorised, Amount: "$39.99", Comment: "The Pragmatic Programmer: 20th Anniversary Edition"},
+			{Account: ledger.Uncategorised, Amount: "$19.98", Comment: "2 × USB-C Cable, 6ft"},
+			{Account: receipt.DefaultFeeAccount, Amount: "$5.99", Comment: "Shipping & Handling"},
+			{Account: ledger.Uncategorised, Amount: "-$5.99", Comment: "discount"},
+			{Account: receipt.DefaultTaxAccount, Amount: "$4.80"},
+		},
+	}, tx)
+	assert.NoError(t, tx.Check())
+}
@@ -0,0 +1,40 @@
+package amex
+
+import (
+	"regexp"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+const (
+	money = `\$\d{1,3}(?:,\d{3})*\.\d{2}`
+	day   = `[A-Z][a-z]{2,8}\.? \d{1,2}, \d{4}`
+)
+
+var alerts email.Alerts
+
+func init() {
+	alerts = email.Alerts{
+		Layouts: []string{"January 2, 2006", "Jan 2, 2006"},
+		Templates: []email.Alert{
+			{Exp: regexp.MustCompile(`(?i)(?:a )?(?:credit|refund) of (?P<amt>` + money + `) from (?P<payee>.+?) (?:was|has been) (?:posted|applied|credited)(?:[^.]*? on (?P<date>` + day + `))?`), Receive: true, Status: ledger.Cleared},
+			{Exp: regexp.MustCompile(`(?i)(?:received your payment of (?P<amt>` + money + `)|your payment of (?P<amt2>` + money + `) (?:was|has been) (?:received|posted))(?:[^.]*? on (?P<date>` + day + `))?`), Receive: true, Status: ledger.Cleared, Payee: "American Express payment"},
+			// "We approved a charge of $1,234.56 at DELTA AIR LINES on May 5, 2025"
+			{Exp: regexp.MustCompile(`(?im)(?:approved an? |a )?(?:charge|purchase|transaction) of (?P<amt>` + money + `) (?:at|with) (?P<payee>.+?)(?: on (?P<date>` + day + `))?(?: (?:was|has been) (?:approved|made|charged)| on (?:your )?card ending|\.\s|$)`), Status: ledger.Pending},
+		},
+	}
+}
+
+type ProviderAmex struct {
+	Account string
+}
+
+func (p *ProviderAmex) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	return alerts.FromText(msg, p.Account)
+}
+
+func (p *ProviderAmex) GetAccount() string {
+	return p.Account
+}
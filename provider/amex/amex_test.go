@@ -0,0 +1,64 @@
+package amex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/gmail/v1"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Tue, 6 May 2025 08:15:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	sent := time.Date(2025, 5, 6, 8, 15, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		message  *gmail.Message
+		expected *ledger.Transaction
+	}{
+		{
+			name: "large purchase",
+			message: emailtest.Multipart(dateHeader, "Large Purchase Approved", `We approved a charge of $1,234.56 at APPLE STORE #R102 on May 5, 2025 on your Card ending in 41007.
+Account Ending: 41007`),
+			expected: &ledger.Transaction{
+				Account: "Amex", Status: ledger.Pending, Payee: "APPLE STORE #R102", Amount: "$1,234.56",
+				Date: time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "purchase without a date",
+			message: emailtest.Multipart(dateHeader, "Transaction Alert", "A purchase of $8.45 with BLUE BOTTLE COFFEE was approved."),
+			expected: &ledger.Transaction{
+				Account: "Amex", Status: ledger.Pending, Payee: "BLUE BOTTLE COFFEE", Amount: "$8.45", Date: sent,
+			},
+		},
+		{
+			name:    "payment received",
+			message: emailtest.Multipart(dateHeader, "We received your payment", "Thank you. We received your payment of $500.00 on May 5, 2025."),
+			expected: &ledger.Transaction{
+				Account: "Amex", Status: ledger.Cleared, Payee: "American Express payment", Amount: "$500.00", IsReceive: true,
+				Date: time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "unrelated email",
+			message: emailtest.Multipart(dateHeader, "Your statement is ready", "Your latest statement is available online."),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ProviderAmex{Account: "Amex"}
+			result, err := p.GetTransaction(tc.message)
+			assert.NoError(t, err)
+			if result != nil {
+				result.Date = result.Date.UTC()
+			}
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
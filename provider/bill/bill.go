@@ -0,0 +1,105 @@
+package bill
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/bills"
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+const (
+	amt = `(?P<amt>\$\d{1,3}(?:,\d{3})*(?:\.\d{2})?)`
+	due = `(?P<due>(?:[A-Z][a-z]{2,8}\.? \d{1,2}(?:st|nd|rd|th)?(?:, \d{4})?)|\d{1,2}/\d{1,2}(?:/\d{2,4})?)`
+)
+
+// statements are the sentences of bill and utility statements giving the
+// amount due and when. The amount and due date may be in separate sentences.
+var statements = []*regexp.Regexp{
+	// "Your bill of $84.21 is due Nov 3"
+	regexp.MustCompile(`(?i)bill (?:of|for) ` + amt + ` (?:is|will be) due (?:on |by )?` + due),
+	// "Your payment of $84.21 is due by November 3, 2025"
+	regexp.MustCompile(`(?i)payment of ` + amt + ` (?:is|will be) due (?:on |by )?` + due),
+	// "Your $84.21 bill is ready. It's due on 11/03/2025."
+	regexp.MustCompile(`(?i)` + amt + ` (?:bill|statement)\b[^$]*?\bdue (?:on |by )?` + due),
+	// "Amount due: $84.21 ... Due date: 11/03/2025"
+	regexp.MustCompile(`(?is)(?:amount|total|balance|payment) due:?\s*` + amt + `.*?(?:due date|due on|due by|pay by):?\s*` + due),
+	// "Due date: Nov 3 ... Amount due: $84.21"
+	regexp.MustCompile(`(?is)(?:due date|due on|due by|pay by):?\s*` + due + `.*?(?:amount|total|balance|payment) due:?\s*` + amt),
+}
+
+var (
+	// fullDates are the layouts of due dates with a year, partialDates of
+	// those without, which are taken to be the next such date.
+	fullDates    = []string{"January 2, 2006", "Jan 2, 2006", "Jan. 2, 2006", "01/02/2006", "1/2/2006", "1/2/06"}
+	partialDates = []string{"January 2", "Jan 2", "Jan. 2", "1/2"}
+	expOrdinal   = regexp.MustCompile(`(\d)(?:st|nd|rd|th)\b`)
+)
+
+// ProviderBill reads bill and utility statements into a payable owed from
+// Account, e.g. "liabilities:payable:comcast", and balanced by the expense.
+// The payee is the sender's name and the due date is tagged, see
+// bills.DueTag. The payment itself is imported from the bank or card that
+// makes it.
+type ProviderBill struct {
+	Account string
+}
+
+func (p *ProviderBill) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	text := email.Text(msg)
+	for _, exp := range statements {
+		match := exp.FindStringSubmatch(text)
+		if len(match) == 0 {
+			continue
+		}
+		t := ledger.Transaction{Account: p.Account, Payee: email.SenderName(msg), Date: email.Sent(msg)}
+		if t.Payee == "" {
+			t.Payee = "Bill"
+		}
+		if t.Date.IsZero() {
+			return nil, errors.New("bill: email has no date")
+		}
+		var dueDate string
+		for i, name := range exp.SubexpNames() {
+			switch name {
+			case "amt":
+				t.Amount = match[i]
+			case "due":
+				dueDate = match[i]
+			}
+		}
+		d, err := parseDue(dueDate, t.Date)
+		if err != nil {
+			return nil, err
+		}
+		t.Tags = map[string]string{bills.DueTag: d.Format(time.DateOnly)}
+		return &t, nil
+	}
+	return nil, nil
+}
+
+// parseDue reads the due date of a statement sent at sent.
+func parseDue(value string, sent time.Time) (time.Time, error) {
+	value = expOrdinal.ReplaceAllString(strings.TrimSpace(value), "$1")
+	for _, layout := range fullDates {
+		if d, err := time.ParseInLocation(layout, value, sent.Location()); err == nil {
+			return d, nil
+		}
+	}
+	var err error
+	for _, layout := range partialDates {
+		var d time.Time
+		if d, err = email.UpcomingDate(layout, value, sent); err == nil {
+			return d, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func (p *ProviderBill) GetAccount() string {
+	return p.Account
+}
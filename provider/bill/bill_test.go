@@ -0,0 +1,75 @@
+package bill
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/mikelu92/emailimport/pkg/bills"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestGetTransaction(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		amount string
+		due    string
+	}{
+		{name: "short", body: "Hi Mike, your bill of $84.21 is due Nov 3. Pay online anytime.", amount: "$84.21", due: "2025-11-03"},
+		{name: "next year", body: "Your bill of $84.21 is due Jan 3rd.", amount: "$84.21", due: "2026-01-03"},
+		{name: "payment", body: "Your payment of $1,204.50 is due by November 3, 2025.", amount: "$1,204.50", due: "2025-11-03"},
+		{name: "ready", body: "Your $62 statement is ready to view. It's due on 11/03/2025.", amount: "$62", due: "2025-11-03"},
+		{name: "rows", body: "Account number 1234\nAmount due: $84.21\nDue date: 11/03/2025\n", amount: "$84.21", due: "2025-11-03"},
+		{name: "due first", body: "Due date: 11/3\nTotal due: $84.21\n", amount: "$84.21", due: "2025-11-03"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &gmail.Message{
+				Payload: &gmail.MessagePart{
+					MimeType: "text/plain",
+					Headers: []*gmail.MessagePartHeader{
+						{Name: "From", Value: `"City Power & Light" <billing@citypower.example>`},
+						{Name: "Subject", Value: "Your statement is ready"},
+						{Name: "Date", Value: "Mon, 13 Oct 2025 09:50:14 +0000"},
+					},
+					Body: &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(tt.body))},
+				},
+			}
+			p := &ProviderBill{Account: "liabilities:payable:power"}
+			tx, err := p.GetTransaction(msg)
+			if err != nil {
+				t.Fatalf("GetTransaction returned error: %v", err)
+			}
+			if tx == nil {
+				t.Fatalf("expected transaction, got nil")
+			}
+			if tx.Amount != tt.amount {
+				t.Fatalf("expected amount %q, got %q", tt.amount, tx.Amount)
+			}
+			if tx.Tags[bills.DueTag] != tt.due {
+				t.Fatalf("expected due %q, got %q", tt.due, tx.Tags[bills.DueTag])
+			}
+			if tx.Payee != "City Power & Light" {
+				t.Fatalf("expected payee from sender, got %q", tx.Payee)
+			}
+			if tx.IsReceive || tx.Status != ledger.Unmarked || tx.Account != "liabilities:payable:power" {
+				t.Fatalf("expected unmarked payable, got %+v", tx)
+			}
+		})
+	}
+}
+
+func TestGetTransactionNotBill(t *testing.T) {
+	msg := &gmail.Message{
+		Payload: &gmail.MessagePart{
+			MimeType: "text/plain",
+			Headers:  []*gmail.MessagePartHeader{{Name: "Date", Value: "Mon, 13 Oct 2025 09:50:14 +0000"}},
+			Body:     &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte("Thanks for your payment of $84.21."))},
+		},
+	}
+	p := &ProviderBill{Account: "liabilities:payable:power"}
+	if tx, err := p.GetTransaction(msg); err != nil || tx != nil {
+		t.Fatalf("expected no transaction, got %+v, %v", tx, err)
+	}
+}
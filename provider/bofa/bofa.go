@@ -0,0 +1,36 @@
+package bofa
+
+import (
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+// alerts are Bank of America email templates: a subject containing one of
+// the phrases and a table of details such as "Amount", "Date" and "Where".
+var alerts = email.Alerts{
+	Payee:   []string{"Where", "Merchant", "Merchant name", "From"},
+	Date:    []string{"Date", "Transaction date", "Posted date", "Payment date"},
+	Layouts: []string{"January 2, 2006", "Jan 2, 2006", "01/02/2006"},
+	Templates: []email.Alert{
+		// "credit" alone would match "Credit card transaction ..."
+		{Subject: []string{"refund", "credit posted", "credit was posted"}, Amount: []string{"Credit amount", "Amount", "Transaction amount"}, Receive: true, Status: ledger.Cleared},
+		// "We've received your credit card payment"; reminders such as "Your
+		// payment is due" neither say so nor list a payment amount
+		{Subject: []string{"received your", "payment received", "payment posted", "payment was posted", "payment has posted"}, Amount: []string{"Payment amount"}, Receive: true, Status: ledger.Cleared, Payee: "Bank of America card payment"},
+		// "Credit card transaction exceeds alert limit you set"
+		{Subject: []string{"transaction", "purchase"}, Amount: []string{"Amount", "Transaction amount"}, Status: ledger.Pending},
+	},
+}
+
+type ProviderBofA struct {
+	Account string
+}
+
+func (p *ProviderBofA) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	return alerts.FromTable(msg, p.Account)
+}
+
+func (p *ProviderBofA) GetAccount() string {
+	return p.Account
+}
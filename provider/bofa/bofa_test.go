@@ -0,0 +1,60 @@
+package bofa
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/gmail/v1"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Tue, 6 May 2025 08:15:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	testCases := []struct {
+		name     string
+		message  *gmail.Message
+		expected *ledger.Transaction
+	}{
+		{
+			name:    "purchase over the alert limit",
+			message: emailtest.HTML(t, dateHeader, "Credit card transaction exceeds alert limit you set", "purchase.html"),
+			expected: &ledger.Transaction{
+				Account: "BofA", Status: ledger.Pending, Payee: "COSTCO WHSE #0123", Amount: "$212.45",
+				Date: time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "payment received",
+			message: emailtest.HTML(t, dateHeader, "We've received your credit card payment", "payment.html"),
+			expected: &ledger.Transaction{
+				Account: "BofA", Status: ledger.Cleared, Payee: "Bank of America card payment", Amount: "$1,500.00", IsReceive: true,
+				Date: time.Date(2025, 5, 6, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "payment reminder",
+			message: emailtest.HTML(t, dateHeader, "Your payment is due", "payment.html"),
+		},
+		{
+			name:    "scheduled payment",
+			message: emailtest.HTML(t, dateHeader, "Your credit card payment is scheduled", "payment.html"),
+		},
+		{
+			name:    "unrelated subject",
+			message: emailtest.HTML(t, dateHeader, "Your statement is ready", "purchase.html"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ProviderBofA{Account: "BofA"}
+			result, err := p.GetTransaction(tc.message)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
@@ -8,14 +8,62 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mikelu92/emailimport/pkg/email"
 	"github.com/mikelu92/emailimport/pkg/ledger"
 	"google.golang.org/api/gmail/v1"
 )
 
-var data *regexp.Regexp
+const (
+	money = `\$\d{1,3}(?:,\d{3})*\.\d{2}`
+	amt   = `(?P<amt>` + money + `)`
+	date  = `(?P<date>[A-Z][a-z]+ \d{1,2}, \d{4})`
+)
+
+var (
+	data       *regexp.Regexp
+	credit     *regexp.Regexp
+	reversal   *regexp.Regexp
+	large      *regexp.Regexp
+	payment    *regexp.Regexp
+	deposit    *regexp.Regexp
+	withdrawal *regexp.Regexp
+	alerts     []alert
+)
+
+// alert is a Capital One email template: a subject containing one of the
+// phrases and a body matching one of the expressions.
+type alert struct {
+	subject []string
+	exps    []*regexp.Regexp
+	receive bool
+	status  ledger.Status
+	// payee is used when the body doesn't name one
+	payee string
+	// repeats marks alerts about a purchase the transaction alert already
+	// records, which are marked processed without a transaction
+	repeats bool
+}
 
 func init() {
-	data, _ = regexp.Compile("(?m)on (?P<date>[A-Z][a-z]+ \\d{1,2}, \\d{4}), at (?P<payee>.+), a pending authorization or purchase in the amount of (?P<amt>\\$\\d{0,}(,?\\d{3})*.\\d{2}) was placed")
+	data = regexp.MustCompile("(?m)on " + date + ", at (?P<payee>.+?), a pending authorization or purchase in the amount of " + amt + " was placed")
+	credit = regexp.MustCompile("(?m)on " + date + ", (?P<payee>.+?) (?:issued a (?:credit|refund)|credited|refunded) (?:in the amount of |of )?" + amt + " to your")
+	reversal = regexp.MustCompile("(?m)on " + date + ", the (?:pending authorization|transaction) at (?P<payee>.+?) (?:in the amount of|for) " + amt + " was reversed")
+	large = regexp.MustCompile("(?m)on " + date + ", (?:at (?P<payee>.+?), )?a (?:purchase|transaction) (?:in the amount of|of) " + amt + "(?: at (?P<payee2>.+?))? (?:was charged|was placed|exceeded)")
+	payment = regexp.MustCompile("(?m)(?:(?:received|posted) your " + amt + " payment|your payment (?:in the amount of|of) (?P<amt2>" + money + ") (?:has )?posted).*? on " + date)
+	deposit = regexp.MustCompile("(?m)on " + date + ", (?:a|your) deposit (?:in the amount of|of) " + amt + "(?: from (?P<payee>.+?))? (?:was|has been) (?:made to|posted to|credited to)")
+	withdrawal = regexp.MustCompile("(?m)on " + date + ", (?:a|an) (?:withdrawal|debit|electronic withdrawal) (?:in the amount of|of) " + amt + "(?: to (?P<payee>.+?))? (?:was|has been) (?:made from|posted to|taken from)")
+
+	alerts = []alert{
+		// "a pending authorization or purchase ... was placed or charged"
+		{subject: []string{"transaction was charged to your account"}, exps: []*regexp.Regexp{data}, status: ledger.Pending},
+		{subject: []string{"credit", "refund", "reversed"}, exps: []*regexp.Regexp{credit, reversal}, receive: true, status: ledger.Cleared},
+		// large purchases are also charged, and alerted, like any other
+		{subject: []string{"large purchase", "exceeds", "over your"}, exps: []*regexp.Regexp{large}, repeats: true},
+		{subject: []string{"payment"}, exps: []*regexp.Regexp{payment}, receive: true, status: ledger.Cleared, payee: "Capital One card payment"},
+		// 360 Checking and Savings
+		{subject: []string{"deposit"}, exps: []*regexp.Regexp{deposit}, receive: true, status: ledger.Cleared, payee: "Deposit"},
+		{subject: []string{"withdrawal", "debit"}, exps: []*regexp.Regexp{withdrawal}, status: ledger.Cleared, payee: "Withdrawal"},
+	}
 }
 
 type ProviderCapitalOne struct {
@@ -23,50 +71,87 @@ type ProviderCapitalOne struct {
 }
 
 func (p *ProviderCapitalOne) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
-	t := ledger.Transaction{Account: p.Account}
-	var match bool
+	var subject string
 	for _, header := range msg.Payload.Headers {
 		if header.Name == "Subject" {
-			if strings.Contains(header.Value, "transaction was charged to your account") {
-				match = true
+			subject = strings.ToLower(header.Value)
+			break
+		}
+	}
+	var candidates []alert
+	for _, a := range alerts {
+		for _, phrase := range a.subject {
+			if strings.Contains(subject, phrase) {
+				candidates = append(candidates, a)
 				break
 			}
 		}
 	}
-	if !match {
+	if len(candidates) == 0 {
 		return nil, nil
 	}
-	result := make(map[string]string)
 
-	body, err := findPlainText(msg.Payload)
-	if err != nil {
-		return nil, err
-	}
-	bodyMsg, err := base64.URLEncoding.DecodeString(body.Data)
-	if err != nil {
-		return nil, fmt.Errorf("unable to decode email message: %w", err)
+	// prefer the plain text part the templates were written for, but don't
+	// give up on emails that only have HTML
+	var bodyMsg string
+	if body, err := findPlainText(msg.Payload); err == nil && body != nil {
+		b, err := decodeBase64(body.Data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode email message: %w", err)
+		}
+		bodyMsg = string(b)
+	} else {
+		bodyMsg = email.Text(msg)
 	}
-	transactionParts := data.FindStringSubmatch(string(bodyMsg))
 
-	for i, name := range data.SubexpNames() {
-		if i != 0 && name != "" {
-			result[name] = transactionParts[i]
+	for _, a := range candidates {
+		for _, exp := range a.exps {
+			match := exp.FindStringSubmatch(bodyMsg)
+			if len(match) == 0 {
+				continue
+			}
+			if a.repeats {
+				return nil, email.ErrNoTransaction
+			}
+			result := make(map[string]string)
+			for i, name := range exp.SubexpNames() {
+				if i != 0 && name != "" && match[i] != "" {
+					result[strings.TrimSuffix(name, "2")] = match[i]
+				}
+			}
+			d, err := time.Parse("January 2, 2006", result["date"])
+			if err != nil {
+				return nil, err
+			}
+			t := ledger.Transaction{
+				Account:   p.Account,
+				Payee:     result["payee"],
+				Amount:    result["amt"],
+				Date:      d,
+				IsReceive: a.receive,
+				Status:    a.status,
+			}
+			if t.Payee == "" {
+				t.Payee = a.payee
+			}
+			return &t, nil
 		}
 	}
-	d, err := time.Parse("January 2, 2006", result["date"])
-	if err != nil {
-		return nil, err
-	}
-	t.Payee = result["payee"]
-	t.Amount = result["amt"]
-	t.Date = d
-	return &t, nil
+	return nil, nil
 }
 
 func (p *ProviderCapitalOne) GetAccount() string {
 	return p.Account
 }
 
+// decodeBase64 tries padded base64url first, then raw (unpadded) base64url.
+func decodeBase64(s string) ([]byte, error) {
+	if b, err := base64.URLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
 var ErrPartNotFound = errors.New("part not found")
 
 func findPlainText(msg *gmail.MessagePart) (*gmail.MessagePartBody, error) {
@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
 	"github.com/mikelu92/emailimport/pkg/ledger"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/api/gmail/v1"
@@ -61,6 +63,7 @@ Please visit your account to view your pending and posted transactions.`)),
 			},
 			expected: &ledger.Transaction{
 				Account: "Capital One", // assuming this is set in provider
+				Status:  ledger.Pending,
 				Payee:   "Grocery Store",
 				Amount:  "$22.43",
 				Date:    time.Date(2025, 4, 16, 0, 0, 0, 0, time.UTC),
@@ -110,11 +113,129 @@ Please visit your account to view your pending and posted transactions.`)),
 			},
 			expected: &ledger.Transaction{
 				Account: "Capital One",
+				Status:  ledger.Pending,
 				Payee:   "Large Box Store #5",
 				Amount:  "$3,096.00",
 				Date:    time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC),
 			},
 		},
+		{
+			name: "credit posted",
+			message: &gmail.Message{
+				Payload: &gmail.MessagePart{
+					Headers: []*gmail.MessagePartHeader{
+						{
+							Name:  "Subject",
+							Value: "A credit was posted to your account",
+						},
+						{
+							Name:  "Content-Type",
+							Value: "multipart/alternative; boundary=\"_----iXOnu5gH/sAz9UXjulqIXQ===_11/85-42097-5F7BE116\"",
+						},
+					},
+					Parts: []*gmail.MessagePart{
+						{
+							Headers: []*gmail.MessagePartHeader{
+								{
+									Name:  "Content-Type",
+									Value: "text/plain; charset=\"UTF-8\"",
+								},
+							},
+							Body: &gmail.MessagePartBody{
+								Data: base64.URLEncoding.EncodeToString([]byte(`Capital One | Venture X
+--
+
+About your Venture X Card ending in 1807
+
+As requested, we're notifying you that on April 18, 2025, Large Box Store #5 issued a credit of $1,049.99 to your Venture X Card.`)),
+							},
+						},
+					},
+				},
+			},
+			expected: &ledger.Transaction{
+				Account:   "Capital One",
+				Status:    ledger.Cleared,
+				Payee:     "Large Box Store #5",
+				Amount:    "$1,049.99",
+				IsReceive: true,
+				Date:      time.Date(2025, 4, 18, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "reversed authorization",
+			message: &gmail.Message{
+				Payload: &gmail.MessagePart{
+					Headers: []*gmail.MessagePartHeader{
+						{
+							Name:  "Subject",
+							Value: "Your transaction was reversed",
+						},
+						{
+							Name:  "Content-Type",
+							Value: "multipart/alternative; boundary=\"_----iXOnu5gH/sAz9UXjulqIXQ===_11/85-42097-5F7BE116\"",
+						},
+					},
+					Parts: []*gmail.MessagePart{
+						{
+							Headers: []*gmail.MessagePartHeader{
+								{
+									Name:  "Content-Type",
+									Value: "text/plain; charset=\"UTF-8\"",
+								},
+							},
+							Body: &gmail.MessagePartBody{
+								Data: base64.URLEncoding.EncodeToString([]byte(`Capital One | Venture X
+--
+
+About your Venture X Card ending in 1807
+
+As requested, we're notifying you that on April 17, 2025, the pending authorization at Grocery Store in the amount of $22.43 was reversed.`)),
+							},
+						},
+					},
+				},
+			},
+			expected: &ledger.Transaction{
+				Account:   "Capital One",
+				Status:    ledger.Cleared,
+				Payee:     "Grocery Store",
+				Amount:    "$22.43",
+				IsReceive: true,
+				Date:      time.Date(2025, 4, 17, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "credit subject with unknown body",
+			message: &gmail.Message{
+				Payload: &gmail.MessagePart{
+					Headers: []*gmail.MessagePartHeader{
+						{
+							Name:  "Subject",
+							Value: "A credit was posted to your account",
+						},
+						{
+							Name:  "Content-Type",
+							Value: "multipart/alternative; boundary=\"_----iXOnu5gH/sAz9UXjulqIXQ===_11/85-42097-5F7BE116\"",
+						},
+					},
+					Parts: []*gmail.MessagePart{
+						{
+							Headers: []*gmail.MessagePartHeader{
+								{
+									Name:  "Content-Type",
+									Value: "text/plain; charset=\"UTF-8\"",
+								},
+							},
+							Body: &gmail.MessagePartBody{
+								Data: base64.URLEncoding.EncodeToString([]byte(`Your statement credit for travel is available.`)),
+							},
+						},
+					},
+				},
+			},
+			expected: nil,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -134,3 +255,93 @@ Please visit your account to view your pending and posted transactions.`)),
 		})
 	}
 }
+
+// dateHeader is the Date header of the alerts built with emailtest.
+const dateHeader = "Sat, 3 May 2025 18:20:00 +0000"
+
+func TestGetTransactionAlerts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		message  *gmail.Message
+		expected *ledger.Transaction
+	}{
+		{
+			name:    "payment posted",
+			message: emailtest.Multipart(dateHeader, "Your credit card payment has posted", "We've posted your $1,250.00 payment to your Quicksilver Card ending in 4242 on May 2, 2025."),
+			expected: &ledger.Transaction{
+				Account: "Capital One", Status: ledger.Cleared, Payee: "Capital One card payment", Amount: "$1,250.00", IsReceive: true,
+				Date: time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "360 checking deposit",
+			message: emailtest.Multipart(dateHeader, "A deposit was made to your 360 Checking account", "We're letting you know that on May 15, 2025, a deposit of $3,102.45 from ACME CORP PAYROLL was made to your 360 Checking account ending in 5678."),
+			expected: &ledger.Transaction{
+				Account: "Capital One", Status: ledger.Cleared, Payee: "ACME CORP PAYROLL", Amount: "$3,102.45", IsReceive: true,
+				Date: time.Date(2025, 5, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "360 savings withdrawal",
+			message: emailtest.Multipart(dateHeader, "A withdrawal was made from your 360 Performance Savings account", "We're letting you know that on May 16, 2025, a withdrawal of $500.00 was made from your 360 Performance Savings account ending in 9999."),
+			expected: &ledger.Transaction{
+				Account: "Capital One", Status: ledger.Cleared, Payee: "Withdrawal", Amount: "$500.00",
+				Date: time.Date(2025, 5, 16, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "payment subject with changed body",
+			message: emailtest.Multipart(dateHeader, "Your payment is scheduled", "Your payment is on its way."),
+		},
+		{
+			name: "charge without a plain text part",
+			message: &gmail.Message{
+				Payload: &gmail.MessagePart{
+					Headers: []*gmail.MessagePartHeader{
+						{Name: "Subject", Value: "A new transaction was charged to your account"},
+						{Name: "Content-Type", Value: "text/html; charset=\"UTF-8\""},
+					},
+					MimeType: "text/html",
+					Body: &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(
+						`<html><body><p>As requested, we're notifying you that on April 16, 2025, at Grocery Store, a pending authorization or purchase in the amount of $22.43 was placed or charged on your Venture X Card.</p></body></html>`,
+					))},
+				},
+			},
+			expected: &ledger.Transaction{
+				Account: "Capital One", Status: ledger.Pending, Payee: "Grocery Store", Amount: "$22.43",
+				Date: time.Date(2025, 4, 16, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "no body",
+			message: &gmail.Message{Payload: &gmail.MessagePart{Headers: []*gmail.MessagePartHeader{{Name: "Subject", Value: "A new transaction was charged to your account"}}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ProviderCapitalOne{Account: "Capital One"}
+			result, err := p.GetTransaction(tc.message)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+// TestGetTransactionLargePurchase checks that a purchase alerted both as a
+// charge and as a large purchase is only booked once.
+func TestGetTransactionLargePurchase(t *testing.T) {
+	p := &ProviderCapitalOne{Account: "Capital One"}
+	charge := emailtest.Multipart(dateHeader, "A new transaction was charged to your account", "As requested, we're notifying you that on May 3, 2025, at BEST ELECTRONICS, a pending authorization or purchase in the amount of $2,399.99 was placed or charged on your Venture X Card.")
+	result, err := p.GetTransaction(charge)
+	assert.NoError(t, err)
+	assert.Equal(t, &ledger.Transaction{
+		Account: "Capital One", Status: ledger.Pending, Payee: "BEST ELECTRONICS", Amount: "$2,399.99",
+		Date: time.Date(2025, 5, 3, 0, 0, 0, 0, time.UTC),
+	}, result)
+
+	large := emailtest.Multipart(dateHeader, "A large purchase was charged to your account", "As requested, we're notifying you that on May 3, 2025, a purchase of $2,399.99 at BEST ELECTRONICS exceeded the amount you set for your Venture X Card.")
+	result, err = p.GetTransaction(large)
+	assert.ErrorIs(t, err, email.ErrNoTransaction)
+	assert.Nil(t, result)
+}
@@ -0,0 +1,87 @@
+package cashapp
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+// Cash App leaves out the cents of whole amounts, "$12"
+const amt = `(?P<amt>\$\d{1,3}(?:,\d{3})*(?:\.\d{2})?)`
+
+// alert is a Cash App receipt, recognised by its subject, which ends with
+// the note of the payment: "You paid $12.50 to Jane Doe for Pizza".
+type alert struct {
+	exp     *regexp.Regexp
+	receive bool
+	// failed and canceled payments don't move any money
+	failed bool
+}
+
+var (
+	alerts []alert
+	expID  *regexp.Regexp
+)
+
+func init() {
+	alerts = []alert{
+		{exp: regexp.MustCompile(`(?i)^(?:Your )?payment (?:to|from) .+? (?:failed|was canceled|was cancelled|was declined)`), failed: true},
+		{exp: regexp.MustCompile(`(?i)^(?:Canceled|Cancelled|Failed|Declined):`), failed: true},
+		{exp: regexp.MustCompile(`^You (?:paid|sent) ` + amt + ` to (?P<payee>.+?)(?: for (?P<note>.+))?$`)},
+		{exp: regexp.MustCompile(`^You paid (?P<payee>.+?) ` + amt + `(?: for (?P<note>.+))?$`)},
+		{exp: regexp.MustCompile(`^You received ` + amt + ` from (?P<payee>.+?)(?: for (?P<note>.+))?$`), receive: true},
+		{exp: regexp.MustCompile(`^(?P<payee>.+?) (?:sent|paid) you ` + amt + `(?: for (?P<note>.+))?$`), receive: true},
+	}
+	expID = regexp.MustCompile(`(?i)Identifier:?\s*#?\s*([A-Z0-9]{4,})`)
+}
+
+type ProviderCashApp struct {
+	Account string
+}
+
+func (p *ProviderCashApp) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	var subject string
+	for _, h := range msg.Payload.Headers {
+		if h.Name == "Subject" {
+			subject = strings.TrimSpace(h.Value)
+			break
+		}
+	}
+	for _, a := range alerts {
+		match := a.exp.FindStringSubmatch(subject)
+		if len(match) == 0 {
+			continue
+		}
+		if a.failed {
+			return nil, email.ErrNoTransaction
+		}
+		t := ledger.Transaction{Account: p.Account, IsReceive: a.receive, Status: ledger.Cleared}
+		for i, name := range a.exp.SubexpNames() {
+			switch name {
+			case "amt":
+				t.Amount = match[i]
+			case "payee":
+				t.Payee = match[i]
+			case "note":
+				t.Note = match[i]
+			}
+		}
+		if m := expID.FindStringSubmatch(email.Text(msg)); len(m) != 0 {
+			t.ID = m[1]
+		}
+		t.Date = email.Sent(msg)
+		if t.Date.IsZero() {
+			return nil, errors.New("cashapp: email has no date")
+		}
+		return &t, nil
+	}
+	return nil, nil
+}
+
+func (p *ProviderCashApp) GetAccount() string {
+	return p.Account
+}
@@ -0,0 +1,66 @@
+package cashapp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/gmail/v1"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Tue, 6 May 2025 08:15:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	sent := time.Date(2025, 5, 6, 8, 15, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		message  *gmail.Message
+		expected *ledger.Transaction
+		err      error
+	}{
+		{
+			name:    "paid with a note",
+			message: emailtest.Text(dateHeader, "You paid $12.50 to Jane Doe for Lunch", "Payment to $janedoe\nIdentifier #D4ER7XQ"),
+			expected: &ledger.Transaction{
+				Account: "Cash App", Status: ledger.Cleared, Payee: "Jane Doe", Amount: "$12.50",
+				Note: "Lunch", ID: "D4ER7XQ", Date: sent,
+			},
+		},
+		{
+			name:    "whole dollars without a note",
+			message: emailtest.Text(dateHeader, "You sent $20 to John Smith", ""),
+			expected: &ledger.Transaction{
+				Account: "Cash App", Status: ledger.Cleared, Payee: "John Smith", Amount: "$20", Date: sent,
+			},
+		},
+		{
+			name:    "sent you",
+			message: emailtest.Text(dateHeader, "John Smith sent you $35 for Concert tickets", "Identifier: #K2PLM8"),
+			expected: &ledger.Transaction{
+				Account: "Cash App", Status: ledger.Cleared, Payee: "John Smith", Amount: "$35", IsReceive: true,
+				Note: "Concert tickets", ID: "K2PLM8", Date: sent,
+			},
+		},
+		{
+			name:    "failed payment",
+			message: emailtest.Text(dateHeader, "Your payment to Jane Doe failed", "Your $12.50 payment to Jane Doe failed."),
+			err:     email.ErrNoTransaction,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ProviderCashApp{Account: "Cash App"}
+			result, err := p.GetTransaction(tc.message)
+			assert.ErrorIs(t, err, tc.err)
+			if result != nil {
+				result.Date = result.Date.UTC()
+			}
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
@@ -1,39 +1,82 @@
 package chase
 
 import (
+	"bytes"
 	"encoding/base64"
 	"errors"
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
 
+	"github.com/mikelu92/emailimport/pkg/email"
 	"github.com/mikelu92/emailimport/pkg/ledger"
 	"golang.org/x/net/html"
 	"google.golang.org/api/gmail/v1"
 )
 
+const amt = `\$\d{1,3}(?:,\d{3})*\.\d{2}`
+
 var (
-	subject *regexp.Regexp
-	last4   *regexp.Regexp
+	subject       *regexp.Regexp
+	subjectCredit *regexp.Regexp
+	last4         *regexp.Regexp
+	amount        *regexp.Regexp
+	bodyAmount    *regexp.Regexp
+	alerts        []alert
 )
 
+// alert is a Chase email template recognised by its subject. Amounts and
+// payees missing from the subject are looked up in the body or defaulted.
+type alert struct {
+	exp     *regexp.Regexp
+	receive bool
+	status  ledger.Status
+	payee   string
+}
+
 func init() {
 	// Accept both “Your …” and “You made a …”, allow thousands separators, and “with” or “at”
-	subject, _ = regexp.Compile(`^(?:Your|You made a) (?P<amt>\$\d{1,3}(?:,\d{3})*\.\d{2}) transaction(?: with| at) (?P<payee>.+)$`)
+	subject, _ = regexp.Compile(`^(?:Your|You made a) (?P<amt>` + amt + `) transaction(?: with| at) (?P<payee>.+)$`)
+	// Refunds and reversals: “You have a $20.00 refund from …”, “Your $20.00 transaction with … was reversed”
+	subjectCredit, _ = regexp.Compile(`^(?:(?:Your|You have an?) (?P<amt>` + amt + `) (?:refund|credit|return)(?: from| with| at) (?P<payee>.+?)|Your (?P<amt2>` + amt + `) transaction(?: with| at) (?P<payee2>.+?) (?:was|has been) (?:reversed|refunded|credited))$`)
 	last4, _ = regexp.Compile(`\d{4}`)
+	amount = regexp.MustCompile(amt)
+	bodyAmount = regexp.MustCompile(`(?i)(?:amount|payment|deposit|debit)[^$]{0,40}(` + amt + `)`)
+
+	alerts = []alert{
+		{exp: subjectCredit, receive: true, status: ledger.Cleared},
+		{exp: subject, status: ledger.Pending},
+		// Zelle: “You sent $25.00 to Jane Doe”, “Jane Doe sent you $50.00”
+		{exp: regexp.MustCompile(`^You(?:'ve| have)? sent (?P<amt>` + amt + `) to (?P<payee>.+?)(?: with Zelle®?)?$`), status: ledger.Cleared},
+		{exp: regexp.MustCompile(`^(?:(?P<payee>.+?) sent you (?P<amt>` + amt + `)|You received (?P<amt2>` + amt + `) from (?P<payee2>.+?))(?: with Zelle®?)?$`), receive: true, status: ledger.Cleared},
+		// “We've received your credit card payment”, “Your credit card payment of $500.00 has posted”
+		{exp: regexp.MustCompile(`^(?:We've received your|Thank you for your|Your) (?:Chase )?(?:credit )?card payment(?: of (?P<amt>` + amt + `))?(?: (?:has )?posted| is complete)?$`), receive: true, status: ledger.Cleared, payee: "Chase card payment"},
+		// “Your direct deposit of $1,234.56 from ACME CORP has posted”
+		{exp: regexp.MustCompile(`^(?:Your|You have a|You received a) direct deposit(?: of (?P<amt>` + amt + `))?(?: from (?P<payee>.+?))?(?: (?:has )?posted)?$`), receive: true, status: ledger.Cleared, payee: "Direct deposit"},
+		// “Your $45.00 ACH debit to CITY UTILITIES has posted”
+		{exp: regexp.MustCompile(`^(?:Your|You have an?) (?:(?P<amt>` + amt + `) )?(?:ACH|electronic) (?:debit|withdrawal|payment)(?: of (?P<amt2>` + amt + `))?(?: (?:to|from|with) (?P<payee>.+?))?(?: (?:has )?posted)?$`), status: ledger.Cleared, payee: "ACH debit"},
+	}
 }
 
+// ProviderChase routes transactions by the last four digits of the account
+// in the alert, falling back to Account, see email.Router.
 type ProviderChase struct {
 	Accounts map[int]string
+	Account  string
+	Strict   bool
 }
 
 func (p *ProviderChase) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
 	t := ledger.Transaction{}
+	var a alert
 	var match []string
 	for _, header := range msg.Payload.Headers {
 		if header.Name == "Subject" {
-			match = subject.FindStringSubmatch(header.Value)
+			for _, a = range alerts {
+				if match = a.exp.FindStringSubmatch(header.Value); len(match) != 0 {
+					break
+				}
+			}
 			break
 		}
 	}
@@ -42,26 +85,25 @@ func (p *ProviderChase) GetTransaction(msg *gmail.Message) (*ledger.Transaction,
 	}
 
 	result := make(map[string]string)
-	for _, header := range msg.Payload.Headers {
-		if header.Name == "Date" {
-			result["date"] = header.Value
-			break
-		}
-	}
-
-	for i, name := range subject.SubexpNames() {
-		if i != 0 && name != "" {
-			result[name] = match[i]
+	for i, name := range a.exp.SubexpNames() {
+		if i != 0 && name != "" && match[i] != "" {
+			result[strings.TrimSuffix(name, "2")] = match[i]
 		}
 	}
-	d, err := time.Parse("Mon, 2 Jan 2006 15:04:05 -0700 (MST)", result["date"])
-	if err != nil {
-		return nil, err
+	// alerts don't repeat the date in the body, the email is sent right away
+	d := email.Sent(msg)
+	if d.IsZero() {
+		return nil, errors.New("chase: email has no date")
 	}
 
 	t.Payee = result["payee"]
+	if t.Payee == "" {
+		t.Payee = a.payee
+	}
 	t.Amount = result["amt"]
 	t.Date = d
+	t.IsReceive = a.receive
+	t.Status = a.status
 
 	// Now get account
 	var bodyData string
@@ -80,63 +122,36 @@ func (p *ProviderChase) GetTransaction(msg *gmail.Message) (*ledger.Transaction,
 		return nil, err
 	}
 
-	ht := html.NewTokenizer(strings.NewReader(string(body)))
-
-	var actFound bool
-loop:
-	for {
-		tt := ht.Next()
-		var token html.Token
-		switch tt {
-		case html.ErrorToken:
-			break loop
-		case html.StartTagToken:
-			token = ht.Token()
-			if token.Data == "tr" {
-				for {
-					tt = ht.Next()
-					token = ht.Token()
-					if tt == html.StartTagToken {
-						if token.Data == "td" {
-							tt = ht.Next()
-							break
-						} else {
-							continue loop
-						}
-					}
-					if tt == html.ErrorToken {
-						break loop
-					}
-				}
-				token = ht.Token()
-				if strings.TrimSpace(token.Data) == "Account" {
-					actFound = true
-					break
-				}
-			}
-			continue loop
-		default:
-			continue loop
-		}
-		ht.Next()      // should be /td
-		tt = ht.Next() // should be td or text token
-		if tt == html.TextToken {
-			ht.Next() // in case it's a text token
+	fields, err := email.TableFields(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	// "Account", or "From account" and "To account" on transfers
+	acct, _ := fields.Find(func(label string) bool {
+		return label == "account" || strings.HasSuffix(label, " account")
+	})
+	router := email.Router{Accounts: p.Accounts, Default: p.Account, Strict: p.Strict}
+	act := router.Default
+	if digits := last4.FindString(acct); digits != "" {
+		i, _ := strconv.Atoi(digits)
+		if act, err = router.Lookup(i); err != nil {
+			return nil, err
 		}
-		ht.Next() // should be the actual account
-		token = ht.Token()
-		digits := last4.Find([]byte(token.Data))
-		i, _ := strconv.Atoi(string(digits))
+	}
+	if act == "" {
+		return nil, nil
+	}
+	t.Account = act
 
-		act, ok := p.Accounts[i]
-		if !ok {
+	if t.Amount == "" {
+		t.Amount = amount.FindString(fields.Get("Amount", "Payment amount", "Deposit amount", "Transaction amount"))
+	}
+	if t.Amount == "" {
+		m := bodyAmount.FindStringSubmatch(bodyText(body))
+		if len(m) == 0 {
 			return nil, nil
 		}
-		t.Account = act
-		break
-	}
-	if !actFound {
-		return nil, nil
+		t.Amount = m[1]
 	}
 	return &t, nil
 }
@@ -146,6 +161,21 @@ func (p *ProviderChase) GetAccount() string {
 
 }
 
+// bodyText returns the text of an HTML body on a single line.
+func bodyText(body []byte) string {
+	var b strings.Builder
+	ht := html.NewTokenizer(strings.NewReader(string(body)))
+	for {
+		switch ht.Next() {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(b.String()), " ")
+		case html.TextToken:
+			b.WriteString(" ")
+			b.Write(ht.Text())
+		}
+	}
+}
+
 // decodeBase64 tries padded base64url first, then raw (unpadded) base64url.
 func decodeBase64(s string) ([]byte, error) {
 	if b, err := base64.URLEncoding.DecodeString(s); err == nil {
@@ -2,9 +2,14 @@ package chase
 
 import (
 	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
 	"google.golang.org/api/gmail/v1"
 )
 
@@ -44,7 +49,243 @@ func TestGetTransactionSampleEmail(t *testing.T) {
 	if !tx.Date.Equal(expectedDate) {
 		t.Fatalf("expected date %v, got %v", expectedDate, tx.Date)
 	}
+	if tx.Status != ledger.Pending {
+		t.Fatalf("expected pending status, got %q", tx.Status)
+	}
 	if tx.Account != "chase:freedom" {
 		t.Fatalf("expected account %q, got %q", "chase:freedom", tx.Account)
 	}
 }
+
+func TestGetTransactionRefund(t *testing.T) {
+	htmlBody := `<html><body><table><tr><td>Account</td><td>Chase Freedom Visa (...8719)</td></tr></table></body></html>`
+	encoded := base64.URLEncoding.EncodeToString([]byte(htmlBody))
+
+	tests := []struct {
+		subject string
+		payee   string
+		amount  string
+	}{
+		{subject: "You have a $20.00 refund from AMAZON MKTPLACE", payee: "AMAZON MKTPLACE", amount: "$20.00"},
+		{subject: "Your $1,204.50 credit from DELTA AIR LINES", payee: "DELTA AIR LINES", amount: "$1,204.50"},
+		{subject: "Your $4.04 transaction with PAYPAL *NY TIMES NYT was reversed", payee: "PAYPAL *NY TIMES NYT", amount: "$4.04"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			msg := &gmail.Message{
+				Payload: &gmail.MessagePart{
+					Headers: []*gmail.MessagePartHeader{
+						{Name: "Subject", Value: tt.subject},
+						{Name: "Date", Value: "Sun, 17 Aug 2025 09:50:14 +0000 (UTC)"},
+						{Name: "Content-Type", Value: "text/html; charset=UTF-8"},
+					},
+					Body: &gmail.MessagePartBody{Data: encoded},
+				},
+			}
+
+			p := &ProviderChase{Accounts: map[int]string{8719: "chase:freedom"}}
+			tx, err := p.GetTransaction(msg)
+			if err != nil {
+				t.Fatalf("GetTransaction returned error: %v", err)
+			}
+			if tx == nil {
+				t.Fatalf("expected transaction, got nil")
+			}
+			if !tx.IsReceive {
+				t.Fatalf("expected refund to be received")
+			}
+			if tx.Status != ledger.Cleared {
+				t.Fatalf("expected cleared status, got %q", tx.Status)
+			}
+			if tx.Amount != tt.amount {
+				t.Fatalf("expected amount %q, got %q", tt.amount, tx.Amount)
+			}
+			if tx.Payee != tt.payee {
+				t.Fatalf("expected payee %q, got %q", tt.payee, tx.Payee)
+			}
+		})
+	}
+}
+
+func TestGetTransactionAccountAlerts(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		body    string
+		payee   string
+		amount  string
+		receive bool
+		account string
+	}{
+		{
+			name:    "zelle sent",
+			subject: "You sent $25.00 to Jane Doe",
+			body:    `<tr><td>From account</td><td>TOTAL CHECKING (...4321)</td></tr>`,
+			payee:   "Jane Doe",
+			amount:  "$25.00",
+			account: "chase:checking",
+		},
+		{
+			name:    "zelle received",
+			subject: "John Smith sent you $1,050.00",
+			body:    `<tr><td>Account</td><td>TOTAL CHECKING (...4321)</td></tr>`,
+			payee:   "John Smith",
+			amount:  "$1,050.00",
+			receive: true,
+			account: "chase:checking",
+		},
+		{
+			name:    "zelle received with zelle suffix",
+			subject: "You received $40.00 from John Smith with Zelle®",
+			body:    `<tr><td>Account</td><td>TOTAL CHECKING (...4321)</td></tr>`,
+			payee:   "John Smith",
+			amount:  "$40.00",
+			receive: true,
+			account: "chase:checking",
+		},
+		{
+			name:    "card payment with amount in body",
+			subject: "We've received your credit card payment",
+			body:    `<tr><td>Account</td><td>Chase Freedom Visa (...8719)</td></tr><tr><td>Payment amount</td><td>$512.34</td></tr>`,
+			payee:   "Chase card payment",
+			amount:  "$512.34",
+			receive: true,
+			account: "chase:freedom",
+		},
+		{
+			name:    "direct deposit",
+			subject: "Your direct deposit of $2,345.67 from ACME CORP PAYROLL has posted",
+			body:    `<tr><td>Account</td><td>TOTAL CHECKING (...4321)</td></tr>`,
+			payee:   "ACME CORP PAYROLL",
+			amount:  "$2,345.67",
+			receive: true,
+			account: "chase:checking",
+		},
+		{
+			name:    "direct deposit without payee",
+			subject: "You have a direct deposit",
+			body:    `<tr><td>Account</td><td>TOTAL CHECKING (...4321)</td></tr><tr><td>Deposit amount</td><td>$100.00</td></tr>`,
+			payee:   "Direct deposit",
+			amount:  "$100.00",
+			receive: true,
+			account: "chase:checking",
+		},
+		{
+			name:    "ach debit",
+			subject: "Your $45.00 ACH debit to CITY UTILITIES has posted",
+			body:    `<tr><td>Account</td><td>TOTAL CHECKING (...4321)</td></tr>`,
+			payee:   "CITY UTILITIES",
+			amount:  "$45.00",
+			account: "chase:checking",
+		},
+	}
+	accounts := map[int]string{8719: "chase:freedom", 4321: "chase:checking"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			htmlBody := `<html><body><table>` + tt.body + `</table></body></html>`
+			msg := &gmail.Message{
+				Payload: &gmail.MessagePart{
+					Headers: []*gmail.MessagePartHeader{
+						{Name: "Subject", Value: tt.subject},
+						{Name: "Date", Value: "Sun, 17 Aug 2025 09:50:14 +0000 (UTC)"},
+						{Name: "Content-Type", Value: "text/html; charset=UTF-8"},
+					},
+					Body: &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(htmlBody))},
+				},
+			}
+
+			p := &ProviderChase{Accounts: accounts}
+			tx, err := p.GetTransaction(msg)
+			if err != nil {
+				t.Fatalf("GetTransaction returned error: %v", err)
+			}
+			if tx == nil {
+				t.Fatalf("expected transaction, got nil")
+			}
+			if tx.Payee != tt.payee {
+				t.Fatalf("expected payee %q, got %q", tt.payee, tx.Payee)
+			}
+			if tx.Amount != tt.amount {
+				t.Fatalf("expected amount %q, got %q", tt.amount, tx.Amount)
+			}
+			if tx.IsReceive != tt.receive {
+				t.Fatalf("expected IsReceive %v, got %v", tt.receive, tx.IsReceive)
+			}
+			if tx.Status != ledger.Cleared {
+				t.Fatalf("expected cleared status, got %q", tx.Status)
+			}
+			if tx.Account != tt.account {
+				t.Fatalf("expected account %q, got %q", tt.account, tx.Account)
+			}
+		})
+	}
+}
+
+// TestGetTransactionTemplates reads the same alert as laid out by the
+// generations of Chase email templates in testdata.
+func TestGetTransactionTemplates(t *testing.T) {
+	files, err := filepath.Glob("testdata/transaction_*.html")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no fixtures found: %v", err)
+	}
+	for _, f := range files {
+		t.Run(filepath.Base(f), func(t *testing.T) {
+			b, err := os.ReadFile(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := &gmail.Message{
+				Payload: &gmail.MessagePart{
+					Headers: []*gmail.MessagePartHeader{
+						{Name: "Subject", Value: "You made a $4.04 transaction with PAYPAL *NY TIMES NYT"},
+						{Name: "Date", Value: "Sun, 17 Aug 2025 09:50:14 +0000 (UTC)"},
+						{Name: "Content-Type", Value: "text/html; charset=UTF-8"},
+					},
+					Body: &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString(b)},
+				},
+			}
+
+			p := &ProviderChase{Accounts: map[int]string{8719: "chase:freedom"}}
+			tx, err := p.GetTransaction(msg)
+			if err != nil {
+				t.Fatalf("GetTransaction returned error: %v", err)
+			}
+			if tx == nil {
+				t.Fatalf("expected transaction, got nil")
+			}
+			if tx.Account != "chase:freedom" {
+				t.Fatalf("expected account %q, got %q", "chase:freedom", tx.Account)
+			}
+			if tx.Amount != "$4.04" {
+				t.Fatalf("expected amount $4.04, got %q", tx.Amount)
+			}
+		})
+	}
+}
+
+func TestGetTransactionUnknownAccount(t *testing.T) {
+	htmlBody := `<html><body><table><tr><td>Account</td><td>Chase Sapphire (...5555)</td></tr></table></body></html>`
+	msg := &gmail.Message{
+		Payload: &gmail.MessagePart{
+			Headers: []*gmail.MessagePartHeader{
+				{Name: "Subject", Value: "You made a $4.04 transaction with PAYPAL *NY TIMES NYT"},
+				{Name: "Date", Value: "Sun, 17 Aug 2025 09:50:14 +0000 (UTC)"},
+				{Name: "Content-Type", Value: "text/html; charset=UTF-8"},
+			},
+			Body: &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(htmlBody))},
+		},
+	}
+
+	p := &ProviderChase{Accounts: map[int]string{8719: "chase:freedom"}}
+	if tx, err := p.GetTransaction(msg); err != nil || tx != nil {
+		t.Fatalf("expected unknown account to be skipped, got %+v, %v", tx, err)
+	}
+	p.Account = "chase:other"
+	if tx, err := p.GetTransaction(msg); err != nil || tx == nil || tx.Account != "chase:other" {
+		t.Fatalf("expected default account, got %+v, %v", tx, err)
+	}
+	p.Strict = true
+	if _, err := p.GetTransaction(msg); !errors.Is(err, email.ErrUnknownSuffix) {
+		t.Fatalf("expected ErrUnknownSuffix, got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package citi
+
+import (
+	"regexp"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+const (
+	money = `\$\d{1,3}(?:,\d{3})*\.\d{2}`
+	day   = `(?:[A-Z][a-z]{2,8} \d{1,2}, \d{4}|\d{2}/\d{2}/\d{4})`
+)
+
+var alerts email.Alerts
+
+func init() {
+	alerts = email.Alerts{
+		Layouts: []string{"January 2, 2006", "Jan 2, 2006", "01/02/2006"},
+		Templates: []email.Alert{
+			// "A credit of $20.00 from AMAZON.COM has been posted to your account"
+			{Exp: regexp.MustCompile(`(?im)A (?:credit|refund) (?:of|for) (?P<amt>` + money + `) from (?P<payee>.+?) (?:was|has been) (?:posted|applied)(?:[^.\n]*? on (?P<date>` + day + `))?`), Receive: true, Status: ledger.Cleared},
+			// "Your payment of $500.00 was posted on 05/05/2025"
+			{Exp: regexp.MustCompile(`(?im)(?:Your|A) payment (?:of|for) (?P<amt>` + money + `) (?:was|has been) (?:posted|received|credited)(?:[^.\n]*? on (?P<date>` + day + `))?`), Receive: true, Status: ledger.Cleared, Payee: "Citi card payment"},
+			// "A $45.67 transaction was made at STARBUCKS on card ending in 1234"
+			{Exp: regexp.MustCompile(`(?im)A (?P<amt>` + money + `) (?:transaction|purchase) was made at (?P<payee>.+?)(?: on (?P<date>` + day + `))?(?: on (?:your )?card ending in|\.\s|$)`), Status: ledger.Pending},
+			// "Your Citi card ending in 1234 was charged $45.67 at STARBUCKS on May 5, 2025."
+			{Exp: regexp.MustCompile(`(?im)was charged (?P<amt>` + money + `) at (?P<payee>.+?)(?: on (?P<date>` + day + `))?(?:\.\s|\.?$)`), Status: ledger.Pending},
+		},
+	}
+}
+
+type ProviderCiti struct {
+	Account string
+}
+
+func (p *ProviderCiti) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	return alerts.FromText(msg, p.Account)
+}
+
+func (p *ProviderCiti) GetAccount() string {
+	return p.Account
+}
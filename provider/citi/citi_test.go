@@ -0,0 +1,71 @@
+package citi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/gmail/v1"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Tue, 6 May 2025 08:15:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	sent := time.Date(2025, 5, 6, 8, 15, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		message  *gmail.Message
+		expected *ledger.Transaction
+	}{
+		{
+			name:    "transaction made",
+			message: emailtest.Multipart(dateHeader, "", "A $45.67 transaction was made at STARBUCKS STORE 1234 on card ending in 5678."),
+			expected: &ledger.Transaction{
+				Account: "Citi", Status: ledger.Pending, Payee: "STARBUCKS STORE 1234", Amount: "$45.67", Date: sent,
+			},
+		},
+		{
+			name:    "card charged",
+			message: emailtest.Multipart(dateHeader, "", "Your Citi card ending in 5678 was charged $1,020.00 at UNITED AIRLINES on May 5, 2025."),
+			expected: &ledger.Transaction{
+				Account: "Citi", Status: ledger.Pending, Payee: "UNITED AIRLINES", Amount: "$1,020.00",
+				Date: time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "credit posted",
+			message: emailtest.Multipart(dateHeader, "", "A credit of $20.00 from AMAZON.COM has been posted to your account on 05/04/2025."),
+			expected: &ledger.Transaction{
+				Account: "Citi", Status: ledger.Cleared, Payee: "AMAZON.COM", Amount: "$20.00", IsReceive: true,
+				Date: time.Date(2025, 5, 4, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "payment posted",
+			message: emailtest.Multipart(dateHeader, "", "Your payment of $500.00 was posted on May 5, 2025. Thank you!"),
+			expected: &ledger.Transaction{
+				Account: "Citi", Status: ledger.Cleared, Payee: "Citi card payment", Amount: "$500.00", IsReceive: true,
+				Date: time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "unrelated email",
+			message: emailtest.Multipart(dateHeader, "", "Your statement is available online."),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ProviderCiti{Account: "Citi"}
+			result, err := p.GetTransaction(tc.message)
+			assert.NoError(t, err)
+			if result != nil {
+				result.Date = result.Date.UTC()
+			}
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package coinbase
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/trade"
+	"google.golang.org/api/gmail/v1"
+)
+
+const money = `\$\d{1,3}(?:,\d{3})*\.\d{2}`
+
+var (
+	// "You bought 0.01234567 BTC for $500.00"; the amount includes the fee
+	// of a purchase and is net of the fee of a sale
+	expTrade    = regexp.MustCompile(`(?i:You )(?P<side>(?i:bought|sold)) (?P<qty>\d*\.?\d+) (?P<sym>[A-Z][A-Z0-9]*) (?i:for) (?P<total>` + money + `)`)
+	expSubtotal = regexp.MustCompile(`(?i)subtotal:?\s*(` + money + `)`)
+	expFee      = regexp.MustCompile(`(?i)(?:coinbase fee|transaction fee|fee):?\s*(` + money + `)`)
+	expID       = regexp.MustCompile(`(?i)(?:transaction|reference) (?:id|#):?\s*([a-f0-9-]{8,})`)
+)
+
+// ProviderCoinbase reads Coinbase buy and sell confirmations. Crypto bought
+// is held in a sub-account per symbol of Accounts.Holdings, at the total
+// value of the order since Coinbase doesn't round its price.
+type ProviderCoinbase struct {
+	Accounts trade.Accounts
+}
+
+func (p *ProviderCoinbase) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	text := email.Text(msg)
+	m := expTrade.FindStringSubmatch(text)
+	if len(m) == 0 {
+		return nil, nil
+	}
+	tr := trade.Trade{Broker: "Coinbase", Quantity: m[2], Symbol: m[3]}
+	switch m[1] {
+	case "sold", "Sold", "SOLD":
+		tr.Sell = true
+	}
+	if f := expFee.FindStringSubmatch(text); len(f) != 0 && f[1] != "$0.00" {
+		tr.Fee = f[1]
+	}
+	if s := expSubtotal.FindStringSubmatch(text); len(s) != 0 {
+		tr.Value = s[1]
+	} else {
+		value, err := subtotal(m[4], tr.Fee, tr.Sell)
+		if err != nil {
+			return nil, err
+		}
+		tr.Value = value
+	}
+	if id := expID.FindStringSubmatch(text); len(id) != 0 {
+		tr.ID = id[1]
+	}
+	tr.Date = email.Sent(msg)
+	if tr.Date.IsZero() {
+		return nil, errors.New("coinbase: email has no date")
+	}
+	t, err := tr.Transaction(p.Accounts)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// subtotal returns the value of the crypto traded from the total of the
+// order: less the fee of a purchase, plus the fee of a sale.
+func subtotal(total, fee string, sell bool) (string, error) {
+	if fee == "" {
+		return total, nil
+	}
+	t, err := ledger.ParseAmount(total)
+	if err != nil {
+		return "", err
+	}
+	f, err := ledger.ParseAmount(fee)
+	if err != nil {
+		return "", err
+	}
+	if !sell {
+		f = f.Neg()
+	}
+	v, err := t.Add(f)
+	if err != nil {
+		return "", err
+	}
+	return v.String(), nil
+}
+
+func (p *ProviderCoinbase) GetAccount() string {
+	return p.Accounts.Cash
+}
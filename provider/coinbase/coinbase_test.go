@@ -0,0 +1,66 @@
+package coinbase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/trade"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/gmail/v1"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Mon, 5 May 2025 14:31:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	sent := time.Date(2025, 5, 5, 14, 31, 0, 0, time.UTC)
+	accounts := trade.Accounts{Holdings: "assets:coinbase", Cash: "assets:checking", Fee: "expenses:fees:coinbase"}
+	testCases := []struct {
+		name     string
+		message  *gmail.Message
+		expected *ledger.Transaction
+	}{
+		{
+			name:    "bought with a subtotal",
+			message: emailtest.Text(dateHeader, "", "You bought 0.01234567 BTC for $500.00\nSubtotal $492.63\nCoinbase fee $7.37\nTransaction ID: 5f1c2e3a-77aa"),
+			expected: &ledger.Transaction{
+				ID: "5f1c2e3a-77aa", Status: ledger.Cleared, Payee: "Coinbase Buy BTC", Amount: "$500.00", Date: sent, Account: "assets:checking",
+				Postings: []ledger.Posting{
+					{Account: "assets:coinbase:BTC", Amount: "0.01234567 BTC", Cost: "$492.63", TotalCost: true},
+					{Account: "expenses:fees:coinbase", Amount: "$7.37"},
+					{Account: "assets:checking", Amount: "-$500.00"},
+				},
+			},
+		},
+		{
+			name:    "sold net of the fee",
+			message: emailtest.Text(dateHeader, "", "You sold 0.5 ETH for $1,485.00\nFee: $15.00"),
+			expected: &ledger.Transaction{
+				Status: ledger.Cleared, Payee: "Coinbase Sell ETH", Amount: "$1485.00", IsReceive: true, Date: sent, Account: "assets:checking",
+				Postings: []ledger.Posting{
+					{Account: "assets:coinbase:ETH", Amount: "-0.5 ETH", Cost: "$1500.00", TotalCost: true},
+					{Account: "expenses:fees:coinbase", Amount: "$15.00"},
+					{Account: "assets:checking", Amount: "$1485.00"},
+				},
+			},
+		},
+		{
+			name:    "not a trade",
+			message: emailtest.Text(dateHeader, "", "You received 0.1 ETH"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ProviderCoinbase{Accounts: accounts}
+			result, err := p.GetTransaction(tc.message)
+			assert.NoError(t, err)
+			if result != nil {
+				result.Date = result.Date.UTC()
+			}
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+// dated replaces the date a provider parsed with the time the email was
+// sent or received.
+type dated struct {
+	Provider
+	source string
+}
+
+func (d *dated) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	t, err := d.Provider.GetTransaction(msg)
+	if err != nil || t == nil {
+		return t, err
+	}
+	date := email.Sent(msg)
+	if d.source == DateReceived {
+		date = email.Received(msg)
+	}
+	if !date.IsZero() {
+		t.Date = date
+	}
+	return t, nil
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestGetDateSource(t *testing.T) {
+	msg := func() *gmail.Message {
+		m := capitalOneAlert("1807")
+		m.Payload.Headers = append(m.Payload.Headers,
+			&gmail.MessagePartHeader{Name: "Received", Value: "by mx.google.com; Thu, 17 Apr 2025 01:02:03 +0000"},
+			&gmail.MessagePartHeader{Name: "Date", Value: "Wed, 16 Apr 2025 20:01:00 -0500 (CDT)"},
+		)
+		return m
+	}
+	tests := []struct {
+		source string
+		want   time.Time
+	}{
+		{source: "", want: time.Date(2025, 4, 16, 0, 0, 0, 0, time.UTC)},
+		{source: DateTransaction, want: time.Date(2025, 4, 16, 0, 0, 0, 0, time.UTC)},
+		{source: DateSent, want: time.Date(2025, 4, 17, 1, 1, 0, 0, time.UTC)},
+		{source: DateReceived, want: time.Date(2025, 4, 17, 1, 2, 3, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		conf := ProviderConfig{Type: "capitalone", Account: "liabilities:capitalone", DateSource: tt.source}
+		if err := conf.Validate(); err != nil {
+			t.Fatalf("Validate returned error: %v", err)
+		}
+		tx, err := Get(conf).GetTransaction(msg())
+		if err != nil || tx == nil {
+			t.Fatalf("GetTransaction returned %+v, %v", tx, err)
+		}
+		if !tx.Date.Equal(tt.want) {
+			t.Errorf("date source %q: got %v, want %v", tt.source, tx.Date.UTC(), tt.want)
+		}
+	}
+
+	if err := (ProviderConfig{Type: "capitalone", DateSource: "body"}).Validate(); err == nil {
+		t.Errorf("expected an error for an unknown date source")
+	}
+	if err := (ProviderConfig{Type: "unknown"}).Validate(); err == nil {
+		t.Errorf("expected an error for an unknown provider type")
+	}
+}
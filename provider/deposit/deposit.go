@@ -0,0 +1,65 @@
+package deposit
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+const amt = `(?P<amt>\$\d{1,3}(?:,\d{3})*\.\d{2})`
+
+// alerts are direct deposit sentences common to bank alerts, for banks
+// without a provider of their own. The payer isn't always named.
+var alerts = []*regexp.Regexp{
+	// "A direct deposit of $2,345.67 from ACME CORP PAYROLL was posted to your account ending in 1234"
+	regexp.MustCompile(`(?i)(?:direct deposit|ACH deposit|ACH credit) (?:of|for) ` + amt + `(?: from (?P<payee>.+?))? (?:was|has been|is) (?:posted|deposited|made|received|credited)`),
+	// "You received a direct deposit of $2,345.67 from ACME CORP."
+	regexp.MustCompile(`(?im)received an? (?:direct|ACH) deposit (?:of|for) ` + amt + `(?: from (?P<payee>.+?))?(?: (?:to|into|in) your|\.\s|\.?$)`),
+	// "ACME CORP sent you a direct deposit of $2,345.67"
+	regexp.MustCompile(`(?im)^(?P<payee>[^\n]+?) sent you an? (?:direct|ACH) deposit (?:of|for) ` + amt),
+	// "Direct deposit received: $2,345.67"
+	regexp.MustCompile(`(?i)direct deposit (?:received|posted):? ` + amt),
+}
+
+// ProviderDeposit reads direct deposit alerts from any bank into Account,
+// or the account matching the last digits in the alert.
+type ProviderDeposit struct {
+	Account string
+}
+
+func (p *ProviderDeposit) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	text := email.Text(msg)
+	for _, exp := range alerts {
+		match := exp.FindStringSubmatch(text)
+		if len(match) == 0 {
+			continue
+		}
+		t := ledger.Transaction{Account: p.Account, IsReceive: true, Status: ledger.Cleared, Payee: "Direct deposit"}
+		for i, name := range exp.SubexpNames() {
+			if match[i] == "" {
+				continue
+			}
+			switch name {
+			case "amt":
+				t.Amount = match[i]
+			case "payee":
+				t.Payee = strings.TrimSpace(match[i])
+			}
+		}
+		// alerts are sent once the deposit posts
+		t.Date = email.Sent(msg)
+		if t.Date.IsZero() {
+			return nil, errors.New("deposit: email has no date")
+		}
+		return &t, nil
+	}
+	return nil, nil
+}
+
+func (p *ProviderDeposit) GetAccount() string {
+	return p.Account
+}
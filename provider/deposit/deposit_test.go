@@ -0,0 +1,61 @@
+package deposit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/gmail/v1"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Fri, 2 May 2025 06:10:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	sent := time.Date(2025, 5, 2, 6, 10, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		message  *gmail.Message
+		expected *ledger.Transaction
+	}{
+		{
+			name:    "direct deposit posted",
+			message: emailtest.Text(dateHeader, "", "A direct deposit of $2,654.25 from ACME CORP PAYROLL was posted to your account ending in 1234."),
+			expected: &ledger.Transaction{
+				Account: "assets:checking", Status: ledger.Cleared, Payee: "ACME CORP PAYROLL", Amount: "$2,654.25", IsReceive: true, Date: sent,
+			},
+		},
+		{
+			name:    "received",
+			message: emailtest.Text(dateHeader, "", "You received a direct deposit of $812.40 from US TREASURY 310.\nLog in to see details."),
+			expected: &ledger.Transaction{
+				Account: "assets:checking", Status: ledger.Cleared, Payee: "US TREASURY 310", Amount: "$812.40", IsReceive: true, Date: sent,
+			},
+		},
+		{
+			name:    "payer not named",
+			message: emailtest.Text(dateHeader, "", "Direct deposit received: $150.00"),
+			expected: &ledger.Transaction{
+				Account: "assets:checking", Status: ledger.Cleared, Payee: "Direct deposit", Amount: "$150.00", IsReceive: true, Date: sent,
+			},
+		},
+		{
+			name:    "not a deposit",
+			message: emailtest.Text(dateHeader, "", "Your balance is below $100.00."),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ProviderDeposit{Account: "assets:checking"}
+			result, err := p.GetTransaction(tc.message)
+			assert.NoError(t, err)
+			if result != nil {
+				result.Date = result.Date.UTC()
+			}
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
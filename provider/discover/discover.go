@@ -9,26 +9,67 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mikelu92/emailimport/pkg/email"
 	"github.com/mikelu92/emailimport/pkg/ledger"
 	htmlparser "golang.org/x/net/html"
 	"google.golang.org/api/gmail/v1"
 )
 
+// Kinds of Discover alerts besides transaction alerts.
+const (
+	kindPayment   = "payment"
+	kindCashback  = "cashback"
+	kindStatement = "statement"
+	kindThreshold = "threshold"
+)
+
+const (
+	money = `\$\d{1,3}(?:,\d{3})*\.\d{2}`
+	day   = `[A-Z][a-z]+ \d{1,2}, \d{4}`
+)
+
+type alert struct {
+	kind string
+	exp  *regexp.Regexp
+}
+
+var (
+	alerts    []alert
+	reClosing *regexp.Regexp
+)
+
 var (
 	reDate     *regexp.Regexp
 	reMerchant *regexp.Regexp
 	reAmount   *regexp.Regexp
+	reCredit   *regexp.Regexp
 )
 
 func init() {
 	// Multiline, order-agnostic label matchers
 	reDate = regexp.MustCompile(`(?m)^(?:Transaction Date|Date):\s*(?P<date>.+)$`)
 	reMerchant = regexp.MustCompile(`(?m)^Merchant:\s*(?P<payee>.+)$`)
-	reAmount = regexp.MustCompile(`(?m)^Amount:\s*(?P<amt>[\$\d,]+\.\d{2})$`)
+	reAmount = regexp.MustCompile(`(?m)^Amount:\s*(?P<amt>-?[\$\d,]+\.\d{2})$`)
+	alerts = []alert{
+		{kind: kindThreshold, exp: regexp.MustCompile(`(?i)(?:transaction|purchase) of (?P<amt>` + money + `) at (?P<payee>.+?)(?: on (?P<date>` + day + `))? (?:exceeds|exceeded|is over|is above) (?:your|the) (?:alert )?(?:threshold|amount)`)},
+		{kind: kindCashback, exp: regexp.MustCompile(`(?i)(?:you(?:'ve| have)? redeemed|redemption of) (?P<amt>` + money + `)(?: of| in)? Cashback Bonus|your (?P<amt2>` + money + `) Cashback Bonus redemption`)},
+		// only payments received or posted, not reminders of payments
+		// scheduled or due
+		{kind: kindPayment, exp: regexp.MustCompile(`(?i)(?:we(?:'ve| have)? received your payment of (?P<amt>` + money + `)|your payment of (?P<amt2>` + money + `)[^.\n]*? (?:has been|was|is now|has) (?:received|posted|processed|credited|applied))(?:[^.\n]*? on (?P<date>` + day + `))?`)},
+		{kind: kindStatement, exp: regexp.MustCompile(`(?is)statement is (?:now )?(?:available|ready).*?New Balance:?\s*(?P<amt>` + money + `)`)},
+	}
+	reClosing = regexp.MustCompile(`(?i)(?:Closing Date|Statement Date|Statement closing date):?\s*(` + day + `)`)
+	// Refund, credit and reversal alerts share the labeled lines above
+	reCredit = regexp.MustCompile(`(?i)\b(?:credit|refund|return)\b[^\n]*\b(?:has been|was|has) (?:posted|issued|processed)\b|\btransaction (?:has been|was) reversed\b`)
 }
 
+// DefaultCashbackAccount receives Cashback Bonus redemptions unless
+// CashbackAccount is set.
+const DefaultCashbackAccount = "income:cashback:discover"
+
 type ProviderDiscover struct {
-	Account string
+	Account         string
+	CashbackAccount string
 }
 
 func (p *ProviderDiscover) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
@@ -101,12 +142,14 @@ func (p *ProviderDiscover) GetTransaction(msg *gmail.Message) (*ledger.Transacti
 	dateStr, hasDate := fields["date"]
 	payee, hasPayee := fields["payee"]
 	amt, hasAmt := fields["amt"]
+	matchedText := bodyText
 
 	// If fields incomplete, try HTML fallback
+	var htmlText string
 	if !hasDate || !hasPayee || !hasAmt {
 		if body, err := findHTML(msg.Payload); err == nil && body != nil && body.Data != "" {
 			if decoded, err := decodeBase64(body.Data); err == nil {
-				htmlText := htmlToText(string(decoded))
+				htmlText = htmlToText(string(decoded))
 				// Re-run regexes on htmlText
 				if m := reDate.FindStringSubmatch(htmlText); len(m) > 0 {
 					for i, name := range reDate.SubexpNames() {
@@ -135,12 +178,22 @@ func (p *ProviderDiscover) GetTransaction(msg *gmail.Message) (*ledger.Transacti
 				amt, hasAmt = fields["amt"]
 				if hasDate && hasPayee && hasAmt {
 					bodySource = "text/html part"
+					matchedText = htmlText
 				}
 			}
 		}
 	}
 
 	if !hasDate || !hasPayee || !hasAmt {
+		// Not a transaction alert, try the other templates on the same texts
+		for _, text := range []string{bodyText, htmlText} {
+			if text == "" {
+				continue
+			}
+			if tx, err := p.getAlert(msg, text); tx != nil || err != nil {
+				return tx, err
+			}
+		}
 		preview := bodyText
 		if len(preview) > 200 {
 			preview = preview[:200] + "..."
@@ -162,12 +215,90 @@ func (p *ProviderDiscover) GetTransaction(msg *gmail.Message) (*ledger.Transacti
 	t.Payee = payee
 	t.Amount = amt
 	t.Date = d
+	t.Status = ledger.Pending
+	if strings.HasPrefix(amt, "-") || reCredit.MatchString(matchedText) {
+		t.Amount = strings.TrimPrefix(amt, "-")
+		t.IsReceive = true
+		t.Status = ledger.Cleared
+	}
 
-	log.Printf("discover.GetTransaction: parsed transaction for account=%q msgID=%q date=%s payee=%q amt=%q bodySource=%s", p.Account, t.ID, d.Format("2006-01-02"), payee, amt, bodySource)
+	log.Printf("discover.GetTransaction: parsed transaction for account=%q msgID=%q date=%s payee=%q amt=%q receive=%t bodySource=%s", p.Account, t.ID, d.Format("2006-01-02"), payee, amt, t.IsReceive, bodySource)
 
 	return &t, nil
 }
 
+// getAlert parses the Discover emails other than transaction alerts. It
+// returns nil when text matches none of them.
+func (p *ProviderDiscover) getAlert(msg *gmail.Message, text string) (*ledger.Transaction, error) {
+	for _, a := range alerts {
+		m := a.exp.FindStringSubmatch(text)
+		if len(m) == 0 {
+			continue
+		}
+		fields := map[string]string{}
+		for i, name := range a.exp.SubexpNames() {
+			if i != 0 && name != "" && m[i] != "" {
+				fields[strings.TrimSuffix(name, "2")] = strings.TrimSpace(m[i])
+			}
+		}
+		if a.kind == kindStatement {
+			if cm := reClosing.FindStringSubmatch(text); len(cm) > 0 {
+				fields["date"] = cm[1]
+			}
+		}
+
+		// alerts without a date in the body are sent when it happens
+		d := email.Sent(msg)
+		if fields["date"] != "" {
+			var err error
+			if d, err = time.Parse("January 2, 2006", fields["date"]); err != nil {
+				return nil, err
+			}
+		}
+		if d.IsZero() {
+			return nil, errors.New("discover: email has no date")
+		}
+
+		t := ledger.Transaction{Account: p.Account, Date: d, Payee: fields["payee"], Amount: fields["amt"], Status: ledger.Cleared}
+		switch a.kind {
+		case kindPayment:
+			t.Payee = "Discover card payment"
+			t.IsReceive = true
+		case kindCashback:
+			// redeemed as a statement credit, Cashback Bonus is income
+			t.Payee = "Discover Cashback Bonus"
+			t.IsReceive = true
+			t.Postings = []ledger.Posting{
+				{Account: p.Account, Amount: t.Amount},
+				{Account: p.cashbackAccount(), Amount: "-" + t.Amount},
+			}
+		case kindStatement:
+			// asserts the balance owed on the card, a negative liability
+			balance, err := ledger.ParseAmount(t.Amount)
+			if err != nil {
+				return nil, err
+			}
+			t.Payee = "Discover statement balance"
+			t.Amount = "$0.00"
+			t.Postings = []ledger.Posting{
+				{Account: p.Account, Amount: "$0.00", Balance: balance.Neg().String()},
+			}
+		case kindThreshold:
+			t.Status = ledger.Pending
+		}
+		log.Printf("discover.GetTransaction: parsed %s alert for account=%q date=%s payee=%q amt=%q", a.kind, p.Account, d.Format("2006-01-02"), t.Payee, t.Amount)
+		return &t, nil
+	}
+	return nil, nil
+}
+
+func (p *ProviderDiscover) cashbackAccount() string {
+	if p.CashbackAccount != "" {
+		return p.CashbackAccount
+	}
+	return DefaultCashbackAccount
+}
+
 func (p *ProviderDiscover) GetAccount() string {
 	return p.Account
 }
@@ -5,11 +5,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
 	"github.com/mikelu92/emailimport/pkg/ledger"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/api/gmail/v1"
 )
 
+// dateHeader is the Date header of every test message.
+const dateHeader = "Tue, 6 May 2025 08:15:00 +0000"
+
 func TestGetTransaction(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -46,6 +50,7 @@ Amount: $1.00`)),
 			},
 			expected: &ledger.Transaction{
 				Account: "Discover",
+				Status:  ledger.Pending,
 				Payee:   "HOLIDAY STATIONS 3826",
 				Amount:  "$1.00",
 				Date:    time.Date(2025, 8, 18, 0, 0, 0, 0, time.UTC),
@@ -80,6 +85,7 @@ Amount: $1.00`)),
 			},
 			expected: &ledger.Transaction{
 				Account: "Discover",
+				Status:  ledger.Pending,
 				Payee:   "HOLIDAY STATIONS 3826",
 				Amount:  "$1.00",
 				Date:    time.Date(2025, 8, 27, 0, 0, 0, 0, time.UTC),
@@ -112,6 +118,7 @@ Amount: $1.00`)),
 			},
 			expected: &ledger.Transaction{
 				Account: "Discover",
+				Status:  ledger.Pending,
 				Payee:   "HOLIDAY STATIONS 3826",
 				Amount:  "$1.00",
 				Date:    time.Date(2025, 8, 27, 0, 0, 0, 0, time.UTC),
@@ -155,11 +162,73 @@ Amount: $1.00`)),
 			},
 			expected: &ledger.Transaction{
 				Account: "Discover",
+				Status:  ledger.Pending,
 				Payee:   "HOLIDAY STATIONS 3826",
 				Amount:  "$1.00",
 				Date:    time.Date(2025, 8, 27, 0, 0, 0, 0, time.UTC),
 			},
 		},
+		{
+			name: "credit posted, text/plain",
+			message: &gmail.Message{
+				Payload: &gmail.MessagePart{
+					Headers: []*gmail.MessagePartHeader{
+						{
+							Name:  "Content-Type",
+							Value: "text/plain; charset=\"UTF-8\"",
+						},
+					},
+					Body: &gmail.MessagePartBody{
+						Data: base64.URLEncoding.EncodeToString([]byte(`A credit has posted to your account.
+Merchant: HOLIDAY STATIONS 3826
+Date: August 29, 2025
+Amount: $1.00`)),
+					},
+				},
+			},
+			expected: &ledger.Transaction{
+				Account:   "Discover",
+				Status:    ledger.Cleared,
+				Payee:     "HOLIDAY STATIONS 3826",
+				Amount:    "$1.00",
+				IsReceive: true,
+				Date:      time.Date(2025, 8, 29, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "negative amount, html-only",
+			message: &gmail.Message{
+				Payload: &gmail.MessagePart{
+					Headers: []*gmail.MessagePartHeader{
+						{
+							Name:  "Content-Type",
+							Value: "multipart/alternative; boundary=mno",
+						},
+					},
+					Parts: []*gmail.MessagePart{
+						{
+							Headers: []*gmail.MessagePartHeader{
+								{
+									Name:  "Content-Type",
+									Value: "text/html; charset=\"UTF-8\"",
+								},
+							},
+							Body: &gmail.MessagePartBody{
+								Data: base64.URLEncoding.EncodeToString([]byte(`<html><body>Merchant: AMAZON MKTPLACE<br/>Date: August 30, 2025<br/>Amount: -$1,020.00</body></html>`)),
+							},
+						},
+					},
+				},
+			},
+			expected: &ledger.Transaction{
+				Account:   "Discover",
+				Status:    ledger.Cleared,
+				Payee:     "AMAZON MKTPLACE",
+				Amount:    "$1,020.00",
+				IsReceive: true,
+				Date:      time.Date(2025, 8, 30, 0, 0, 0, 0, time.UTC),
+			},
+		},
 		{
 			name: "non-matching content",
 			message: &gmail.Message{
@@ -193,3 +262,87 @@ Amount: $1.00`)),
 		})
 	}
 }
+
+func TestGetTransactionAlerts(t *testing.T) {
+	// the cashback alert has no date of its own
+	sent := time.Date(2025, 5, 6, 8, 15, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		message  *gmail.Message
+		expected *ledger.Transaction
+	}{
+		{
+			name:    "payment received",
+			message: emailtest.Multipart(dateHeader, "", "Thank you! We've received your payment of $1,250.00 to your Discover card ending in 1234 on May 5, 2025."),
+			expected: &ledger.Transaction{
+				Account: "Discover", Status: ledger.Cleared, Payee: "Discover card payment", Amount: "$1,250.00", IsReceive: true,
+				Date: time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "payment posted",
+			message: emailtest.Multipart(dateHeader, "", "Your payment of $80.00 to your Discover card ending in 1234 has posted."),
+			expected: &ledger.Transaction{
+				Account: "Discover", Status: ledger.Cleared, Payee: "Discover card payment", Amount: "$80.00", IsReceive: true, Date: sent,
+			},
+		},
+		{
+			name:     "payment scheduled",
+			message:  emailtest.Multipart(dateHeader, "", "Your payment of $80.00 is scheduled for May 9, 2025."),
+			expected: nil,
+		},
+		{
+			name:     "payment due",
+			message:  emailtest.Multipart(dateHeader, "", "Your payment of $35.00 is due on May 25, 2025. Pay now to avoid a late fee."),
+			expected: nil,
+		},
+		{
+			name:    "cashback redemption",
+			message: emailtest.Multipart(dateHeader, "", "You've redeemed $25.00 Cashback Bonus as a statement credit."),
+			expected: &ledger.Transaction{
+				Account: "Discover", Status: ledger.Cleared, Payee: "Discover Cashback Bonus", Amount: "$25.00", IsReceive: true, Date: sent,
+				Postings: []ledger.Posting{
+					{Account: "Discover", Amount: "$25.00"},
+					{Account: DefaultCashbackAccount, Amount: "-$25.00"},
+				},
+			},
+		},
+		{
+			name: "statement available",
+			message: emailtest.Multipart(dateHeader, "", `Your statement is now available.
+Statement closing date: May 3, 2025
+New Balance: $1,432.10
+Minimum Payment Due: $35.00`),
+			expected: &ledger.Transaction{
+				Account: "Discover", Status: ledger.Cleared, Payee: "Discover statement balance", Amount: "$0.00",
+				Date: time.Date(2025, 5, 3, 0, 0, 0, 0, time.UTC),
+				Postings: []ledger.Posting{
+					{Account: "Discover", Amount: "$0.00", Balance: "-$1432.10"},
+				},
+			},
+		},
+		{
+			name:    "alert threshold",
+			message: emailtest.Multipart(dateHeader, "", "A transaction of $612.00 at DELTA AIR LINES on May 4, 2025 exceeds your alert threshold of $500.00."),
+			expected: &ledger.Transaction{
+				Account: "Discover", Status: ledger.Pending, Payee: "DELTA AIR LINES", Amount: "$612.00",
+				Date: time.Date(2025, 5, 4, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ProviderDiscover{Account: "Discover"}
+			result, err := p.GetTransaction(tc.message)
+			assert.NoError(t, err)
+			if result != nil {
+				result.Date = result.Date.UTC()
+			}
+			assert.Equal(t, tc.expected, result)
+			if result != nil {
+				assert.NoError(t, result.Check())
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package doordash
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/receipt"
+	"google.golang.org/api/gmail/v1"
+)
+
+var (
+	layout = receipt.Layout{
+		Subtotal: []string{"Subtotal"},
+		Fees:     []string{"Delivery Fee", "Service Fee", "Small Order Fee", "Expanded Range Fee", "Long Distance Fee"},
+		Discount: []string{"Promotion", "Discount", "DashPass Savings", "Credits Applied"},
+		Tax:      []string{"Taxes", "Estimated Tax", "Tax"},
+		Tip:      []string{"Dasher Tip", "Tip"},
+		Total:    []string{"Total", "Total Charged"},
+	}
+	// "Order Confirmation for Jane from Chipotle"
+	expStore = regexp.MustCompile(`(?i)\bfrom (.+?)\s*$`)
+)
+
+// ProviderDoorDash reads DoorDash order confirmations. Account is the card
+// or account orders are paid with.
+type ProviderDoorDash struct {
+	Account  string
+	Accounts receipt.Accounts
+}
+
+func (p *ProviderDoorDash) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	body, err := email.HTML(msg)
+	if err != nil || body == nil {
+		return nil, err
+	}
+	fields, err := email.TableFields(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r, ok := receipt.Parse(fields, layout)
+	if !ok {
+		return nil, nil
+	}
+	r.Merchant = "DoorDash"
+	for _, h := range msg.Payload.Headers {
+		if h.Name != "Subject" {
+			continue
+		}
+		if m := expStore.FindStringSubmatch(h.Value); m != nil {
+			r.Note = m[1]
+		}
+		break
+	}
+	r.Date = email.Sent(msg)
+	if r.Date.IsZero() {
+		return nil, errors.New("doordash: email has no date")
+	}
+	t, err := r.Transaction(p.Account, p.Accounts)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (p *ProviderDoorDash) GetAccount() string {
+	return p.Account
+}
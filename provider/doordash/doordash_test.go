@@ -0,0 +1,41 @@
+package doordash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/receipt"
+	"github.com/stretchr/testify/assert"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Mon, 5 May 2025 19:42:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	p := &ProviderDoorDash{Account: "liabilities:card"}
+	tx, err := p.GetTransaction(emailtest.HTML(t, dateHeader, "Order Confirmation for Jane from Thai Basil Kitchen", "order.html"))
+	assert.NoError(t, err)
+	if tx != nil {
+		tx.Date = tx.Date.UTC()
+	}
+	assert.Equal(t, &ledger.Transaction{
+		Status: ledger.Cleared, Payee: "DoorDash", Note: "Thai Basil Kitchen", Amount: "$44.24", Date: time.Date(2025, 5, 5, 19, 42, 0, 0, time.UTC), Account: "liabilities:card",
+		Postings: []ledger.Posting{
+			{Account: "liabilities:card", Amount: "-$44.24"},
+			{Account: ledger.Uncategorised, Amount: "$15.95", Comment: "Pad See Ew"},
+			{Account: ledger.Uncategorised, Amount: "$16.50", Comment: "Green Curry"},
+			{Account: receipt.DefaultFeeAccount, Amount: "$0.00", Comment: "Delivery Fee"},
+			{Account: receipt.DefaultFeeAccount, Amount: "$4.87", Comment: "Service Fee"},
+			{Account: ledger.Uncategorised, Amount: "-$2.00", Comment: "discount"},
+			{Account: receipt.DefaultTaxAccount, Amount: "$2.92"},
+			{Account: receipt.DefaultTipAccount, Amount: "$6.00"},
+		},
+	}, tx)
+	assert.NoError(t, tx.Check())
+
+	tx, err = p.GetTransaction(emailtest.HTML(t, dateHeader, "Your Dasher is on the way", "../../amazon/testdata/order.html"))
+	assert.NoError(t, err)
+	assert.Nil(t, tx)
+}
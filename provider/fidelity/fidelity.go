@@ -0,0 +1,86 @@
+package fidelity
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/trade"
+	"google.golang.org/api/gmail/v1"
+)
+
+const (
+	qty   = `(?P<qty>\d{1,3}(?:,\d{3})*(?:\.\d+)?|\.\d+)`
+	sym   = `(?P<sym>[A-Z][A-Z0-9.]*)`
+	price = `(?P<price>\$\d{1,3}(?:,\d{3})*\.\d+)`
+)
+
+var (
+	confirmations = []*regexp.Regexp{
+		// "Your order to buy 10 shares of VTI was executed at an average price of $220.00"
+		regexp.MustCompile(`(?s)(?i:order to )(?P<side>(?i:buy|sell)) ` + qty + ` (?i:shares? of) ` + sym + `\b.*?(?i:executed|filled) at (?i:an average price of )?` + price),
+		// "You bought 10 shares of VTI at $220.00"
+		regexp.MustCompile(`(?i:You )(?P<side>(?i:bought|sold)) ` + qty + ` (?i:shares? of) ` + sym + ` at ` + price),
+	}
+	expFee       = regexp.MustCompile(`(?i)(?:commission|fees?)(?:\s*&\s*fees)?:?\s*(\$\d{1,3}(?:,\d{3})*\.\d{2})`)
+	expOrder     = regexp.MustCompile(`(?i)(?:order number|reference (?:number|#)):?\s*([A-Z0-9-]{5,})`)
+	expTradeDate = regexp.MustCompile(`(?i)trade date:?\s*(\d{2}/\d{2}/\d{4})`)
+)
+
+// ProviderFidelity reads Fidelity trade confirmations into lots held in a
+// sub-account per symbol of Accounts.Holdings.
+type ProviderFidelity struct {
+	Accounts trade.Accounts
+}
+
+func (p *ProviderFidelity) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	text := email.Text(msg)
+	for _, exp := range confirmations {
+		match := exp.FindStringSubmatch(text)
+		if len(match) == 0 {
+			continue
+		}
+		tr := trade.Trade{Broker: "Fidelity"}
+		for i, name := range exp.SubexpNames() {
+			switch name {
+			case "side":
+				side := strings.ToLower(match[i])
+				tr.Sell = side == "sell" || side == "sold"
+			case "qty":
+				tr.Quantity = match[i]
+			case "sym":
+				tr.Symbol = match[i]
+			case "price":
+				tr.Price = match[i]
+			}
+		}
+		if m := expFee.FindStringSubmatch(text); len(m) != 0 && m[1] != "$0.00" {
+			tr.Fee = m[1]
+		}
+		if m := expOrder.FindStringSubmatch(text); len(m) != 0 {
+			tr.ID = m[1]
+		}
+		tr.Date = email.Sent(msg)
+		if m := expTradeDate.FindStringSubmatch(text); len(m) != 0 {
+			if d, err := time.Parse("01/02/2006", m[1]); err == nil {
+				tr.Date = d
+			}
+		}
+		if tr.Date.IsZero() {
+			return nil, errors.New("fidelity: email has no date")
+		}
+		t, err := tr.Transaction(p.Accounts)
+		if err != nil {
+			return nil, err
+		}
+		return &t, nil
+	}
+	return nil, nil
+}
+
+func (p *ProviderFidelity) GetAccount() string {
+	return p.Accounts.Cash
+}
@@ -0,0 +1,69 @@
+package fidelity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/trade"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/gmail/v1"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Mon, 5 May 2025 14:31:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	sent := time.Date(2025, 5, 5, 14, 31, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		message  *gmail.Message
+		expected *ledger.Transaction
+	}{
+		{
+			name: "order executed",
+			message: emailtest.Text(dateHeader, "Your trade has been executed", `Your order to buy 10 shares of VTI in your Individual account
+ending in 4321 was executed at an average price of $220.00.
+Order number: 24E0ABCD1
+Trade date: 05/05/2025`),
+			expected: &ledger.Transaction{
+				ID: "24E0ABCD1", Status: ledger.Cleared, Payee: "Fidelity Buy VTI", Amount: "$2200.00",
+				Date: time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC), Account: "assets:fidelity:cash",
+				Postings: []ledger.Posting{
+					{Account: "assets:fidelity:VTI", Amount: "10 VTI", Cost: "$220.00"},
+					{Account: "assets:fidelity:cash", Amount: "-$2200.00"},
+				},
+			},
+		},
+		{
+			name:    "sold with a fee",
+			message: emailtest.Text(dateHeader, "Your trade has been executed", "You sold 1,200 shares of F at $11.0525.\nFees: $0.03"),
+			expected: &ledger.Transaction{
+				Status: ledger.Cleared, Payee: "Fidelity Sell F", Amount: "$13262.97", IsReceive: true,
+				Date: sent, Account: "assets:fidelity:cash",
+				Postings: []ledger.Posting{
+					{Account: "assets:fidelity:F", Amount: "-1200 F", Cost: "$11.0525"},
+					{Account: trade.DefaultFeeAccount, Amount: "$0.03"},
+					{Account: "assets:fidelity:cash", Amount: "$13262.97"},
+				},
+			},
+		},
+		{
+			name:    "not a trade",
+			message: emailtest.Text(dateHeader, "Your trade has been executed", "Your statement is ready."),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ProviderFidelity{Accounts: trade.Accounts{Holdings: "assets:fidelity", Cash: "assets:fidelity:cash"}}
+			result, err := p.GetTransaction(tc.message)
+			assert.NoError(t, err)
+			if result != nil {
+				result.Date = result.Date.UTC()
+			}
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
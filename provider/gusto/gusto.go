@@ -0,0 +1,74 @@
+package gusto
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/payroll"
+	"google.golang.org/api/gmail/v1"
+)
+
+var (
+	layout = payroll.Layout{
+		Gross:  []string{"Gross Pay", "Gross Earnings"},
+		Net:    []string{"Net Pay", "Take Home Pay"},
+		Ignore: []string{"Employee Taxes", "Employee Deductions", "Total Taxes", "Total Deductions", "Hours"},
+	}
+	// "Your Acme Corp paystub is ready"
+	expEmployer = regexp.MustCompile(`(?i)^Your (.+?) pay ?stub\b`)
+)
+
+// ProviderGusto reads Gusto paystub emails. Account is where the net pay is
+// deposited.
+type ProviderGusto struct {
+	Account  string
+	Accounts payroll.Accounts
+}
+
+func (p *ProviderGusto) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	body, err := email.HTML(msg)
+	if err != nil || body == nil {
+		return nil, err
+	}
+	fields, err := email.TableFields(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	s, ok := payroll.Parse(fields, layout)
+	if !ok {
+		return nil, nil
+	}
+	s.Employer = "Gusto payroll"
+	for _, h := range msg.Payload.Headers {
+		if h.Name != "Subject" {
+			continue
+		}
+		if m := expEmployer.FindStringSubmatch(h.Value); m != nil {
+			s.Employer = m[1]
+		}
+		break
+	}
+	s.Date = email.Sent(msg)
+	for _, format := range []string{"Jan 2, 2006", "January 2, 2006", "01/02/2006"} {
+		if d, err := time.Parse(format, fields.Get("Pay Date", "Payday", "Check Date")); err == nil {
+			s.Date = d
+			break
+		}
+	}
+	if s.Date.IsZero() {
+		return nil, errors.New("gusto: email has no date")
+	}
+	t, err := s.Transaction(p.Account, p.Accounts)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (p *ProviderGusto) GetAccount() string {
+	return p.Account
+}
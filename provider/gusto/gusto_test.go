@@ -0,0 +1,36 @@
+package gusto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/payroll"
+	"github.com/stretchr/testify/assert"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Wed, 14 May 2025 16:00:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	p := &ProviderGusto{Account: "assets:checking", Accounts: payroll.Accounts{Income: "income:salary:acme"}}
+	tx, err := p.GetTransaction(emailtest.HTML(t, dateHeader, "Your Acme Corp paystub is ready", "paystub.html"))
+	assert.NoError(t, err)
+	// the stub only totals taxes and deductions, which are left to the
+	// deduction account
+	assert.Equal(t, &ledger.Transaction{
+		Status:    ledger.Cleared,
+		Payee:     "Acme Corp",
+		Amount:    "$2,272.66",
+		Date:      time.Date(2025, 5, 15, 0, 0, 0, 0, time.UTC),
+		Account:   "assets:checking",
+		IsReceive: true,
+		Postings: []ledger.Posting{
+			{Account: "assets:checking", Amount: "$2,272.66"},
+			{Account: "income:salary:acme", Amount: "-$3125.00"},
+			{Account: payroll.DefaultDeductionAccount, Amount: "$852.34", Comment: "other deductions"},
+		},
+	}, tx)
+	assert.NoError(t, tx.Check())
+}
@@ -7,28 +7,66 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mikelu92/emailimport/pkg/email"
 	"github.com/mikelu92/emailimport/pkg/ledger"
 	"google.golang.org/api/gmail/v1"
 )
 
 var (
-	expSent  *regexp.Regexp
-	expSent2 *regexp.Regexp
-	expRec   *regexp.Regexp
+	expSent   *regexp.Regexp
+	expSent2  *regexp.Regexp
+	expRec    *regexp.Regexp
+	expRefund *regexp.Regexp
+	expRef    *regexp.Regexp
+	expID     *regexp.Regexp
 )
 
 func init() {
 	expSent, _ = regexp.Compile("You sent (?P<amt>\\$\\d+\\.\\d+).*to (?P<payee>(\\S+\\s)+)(YOUR NOTE TO|Transaction Details)")
 	expSent2, _ = regexp.Compile("Details Transaction ID: (?P<id>\\S+) (?P<date>.*)")
 	expRec, _ = regexp.Compile("Hello, \\S+\\s\\S+ (?P<payee>.*) sent you (?P<amt>\\$\\d+\\.\\d+).*(Note from.*: (?P<note>.*))? Transaction Details (Transaction ID (?P<id>\\S+))?")
+	expRefund, _ = regexp.Compile("Hello, \\S+\\s\\S+ (?P<payee>.*?) (?:sent|issued) you a refund of (?P<amt>\\$\\d+\\.\\d+)")
+	expRef, _ = regexp.Compile("Original transaction ID:? (?P<ref>\\w+)")
+	expID, _ = regexp.Compile("Transaction ID:? (?P<id>\\w+)")
 }
 
+// DefaultFeeAccount receives PayPal fees unless FeeAccount is set.
+const DefaultFeeAccount = "expenses:fees:paypal"
+
 type ProviderPaypal struct {
-	Account string
+	Account    string
+	FeeAccount string
 }
 
 func (p *ProviderPaypal) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	r, err := parseReceipt(msg)
+	if err != nil {
+		return nil, err
+	}
+	if r != nil {
+		feeAccount := p.FeeAccount
+		if feeAccount == "" {
+			feeAccount = DefaultFeeAccount
+		}
+		t, err := r.transaction(p.Account, feeAccount)
+		if err != nil {
+			return nil, err
+		}
+		if t.Date.IsZero() {
+			t.Date, err = date(msg, "")
+			if err != nil {
+				return nil, err
+			}
+		}
+		return &t, nil
+	}
+
+	// Older or text-only receipts: fall back to the snippet, which Gmail
+	// truncates so notes, IDs and dates may be missing.
 	t := ledger.Transaction{Account: p.Account}
+	if refund := expRefund.FindStringSubmatch(msg.Snippet); len(refund) != 0 {
+		return p.getRefund(msg, refund)
+	}
 	exp := expSent
 	match := exp.FindStringSubmatch(msg.Snippet)
 	if len(match) == 0 {
@@ -64,22 +102,9 @@ func (p *ProviderPaypal) GetTransaction(msg *gmail.Message) (*ledger.Transaction
 		}
 	}
 
-	d, err := time.Parse("January 2, 2006", result["date"])
+	d, err := date(msg, result["date"])
 	if err != nil {
-		var dateString string
-		for _, h := range msg.Payload.Headers {
-			if h.Name == "Date" {
-				dateString = h.Value
-				break
-			}
-		}
-		if dateString == "" {
-			return nil, errors.New("Unable to find date header")
-		}
-		d, err = time.Parse("Mon, 02 Jan 2006 15:04:05 -0700", dateString)
-		if err != nil {
-			return nil, err
-		}
+		return nil, err
 	}
 
 	t.ID = result["id"]
@@ -87,9 +112,52 @@ func (p *ProviderPaypal) GetTransaction(msg *gmail.Message) (*ledger.Transaction
 	t.Amount = result["amt"]
 	t.Note = result["note"]
 	t.Date = d
+	// PayPal only notifies about completed payments
+	t.Status = ledger.Cleared
+	return &t, nil
+}
+
+// getRefund handles "... sent you a refund of $X" emails, linking the refund
+// to the original payment when its transaction ID is included.
+func (p *ProviderPaypal) getRefund(msg *gmail.Message, match []string) (*ledger.Transaction, error) {
+	t := ledger.Transaction{Account: p.Account, IsReceive: true, Status: ledger.Cleared}
+	for i, name := range expRefund.SubexpNames() {
+		switch name {
+		case "payee":
+			t.Payee = strings.TrimSpace(match[i])
+		case "amt":
+			t.Amount = match[i]
+		}
+	}
+	snippet := msg.Snippet
+	if ref := expRef.FindStringSubmatch(snippet); len(ref) != 0 {
+		t.Ref = ref[1]
+		snippet = strings.Replace(snippet, ref[0], "", 1)
+	}
+	if id := expID.FindStringSubmatch(snippet); len(id) != 0 {
+		t.ID = id[1]
+	}
+	d, err := date(msg, "")
+	if err != nil {
+		return nil, err
+	}
+	t.Date = d
 	return &t, nil
 }
 
+// date parses the transaction date from the email, falling back to the Date
+// header.
+func date(msg *gmail.Message, s string) (time.Time, error) {
+	d, err := time.Parse("January 2, 2006", s)
+	if err == nil {
+		return d, nil
+	}
+	if d := email.Sent(msg); !d.IsZero() {
+		return d, nil
+	}
+	return time.Time{}, errors.New("Unable to find date header")
+}
+
 func (p *ProviderPaypal) GetAccount() string {
 	return p.Account
 }
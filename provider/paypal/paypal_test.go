@@ -0,0 +1,251 @@
+package paypal
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/gmail/v1"
+)
+
+// receiptEmail lays out a receipt the way PayPal does: a headline followed by
+// a table of label and value cells.
+func receiptEmail(headline string, rows ...[2]string) *gmail.Message {
+	body := `<html><head><style>td { color: #333; }</style></head><body><table>
+<tr><td><span style="font-size:32px">` + headline + `</span></td></tr>
+<tr><td><table>`
+	for _, r := range rows {
+		body += fmt.Sprintf("\n<tr><td><strong>%s</strong><br/><span>%s</span></td></tr>", r[0], r[1])
+	}
+	body += "</table></td></tr></table></body></html>"
+	return &gmail.Message{
+		Snippet: headline,
+		Payload: &gmail.MessagePart{
+			Headers: []*gmail.MessagePartHeader{
+				{Name: "Date", Value: "Tue, 02 Sep 2025 10:58:36 -0700"},
+				{Name: "Content-Type", Value: "multipart/alternative; boundary=abc"},
+			},
+			Parts: []*gmail.MessagePart{
+				{
+					Headers: []*gmail.MessagePartHeader{{Name: "Content-Type", Value: "text/plain; charset=UTF-8"}},
+					Body:    &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(headline))},
+				},
+				{
+					Headers: []*gmail.MessagePartHeader{{Name: "Content-Type", Value: "text/html; charset=UTF-8"}},
+					Body:    &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(body))},
+				},
+			},
+		},
+	}
+}
+
+func TestGetTransaction(t *testing.T) {
+	testCases := []struct {
+		name     string
+		message  *gmail.Message
+		expected *ledger.Transaction
+	}{
+		{
+			name: "refund with original transaction",
+			message: &gmail.Message{
+				Snippet: "Hello, Jane Doe Book Store sent you a refund of $12.00 USD Refund details Transaction ID: 9RF12345AB Original transaction ID: 4PX98765CD",
+				Payload: &gmail.MessagePart{
+					Headers: []*gmail.MessagePartHeader{
+						{Name: "Date", Value: "Tue, 02 Sep 2025 10:58:36 -0700"},
+					},
+				},
+			},
+			expected: &ledger.Transaction{
+				Account:   "assets:paypal",
+				Status:    ledger.Cleared,
+				Payee:     "Book Store",
+				Amount:    "$12.00",
+				IsReceive: true,
+				ID:        "9RF12345AB",
+				Ref:       "4PX98765CD",
+				Date:      time.Date(2025, 9, 2, 10, 58, 36, 0, time.FixedZone("", -7*60*60)),
+			},
+		},
+		{
+			name: "payment sent",
+			message: receiptEmail("You sent $1,250.00 USD to Jane Doe",
+				[2]string{"Note to Jane Doe", "rent &amp; utilities"},
+				[2]string{"Transaction ID", "4PX98765CD"},
+				[2]string{"Transaction date", "Aug 29, 2025"},
+				[2]string{"Paid with", "PayPal balance $1,250.00 USD"},
+			),
+			expected: &ledger.Transaction{
+				Account: "assets:paypal",
+				Status:  ledger.Cleared,
+				Payee:   "Jane Doe",
+				Amount:  "$1,250.00",
+				Note:    "rent & utilities",
+				ID:      "4PX98765CD",
+				Date:    time.Date(2025, 8, 29, 0, 0, 0, 0, time.UTC),
+				Tags:    map[string]string{"funding": "PayPal balance"},
+			},
+		},
+		{
+			name: "payment received with fee",
+			message: receiptEmail("John Smith sent you $40.00 USD",
+				[2]string{"Note from John Smith", "concert tickets"},
+				[2]string{"Transaction ID", "7YT12345EF"},
+				[2]string{"Transaction date", "September 1, 2025"},
+				[2]string{"Fee", "-$1.46 USD"},
+			),
+			expected: &ledger.Transaction{
+				Account:   "assets:paypal",
+				Status:    ledger.Cleared,
+				Payee:     "John Smith",
+				Amount:    "$38.54",
+				IsReceive: true,
+				Note:      "concert tickets",
+				ID:        "7YT12345EF",
+				Date:      time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+				Tags:      map[string]string{"fee": "$1.46"},
+				Postings: []ledger.Posting{
+					{Account: "assets:paypal", Amount: "$38.54"},
+					{Account: "expenses:fees:paypal", Amount: "$1.46"},
+					{Account: "e.FIXME", Amount: "-$40.00"},
+				},
+			},
+		},
+		{
+			name: "merchant purchase in foreign currency",
+			message: receiptEmail("You paid €25.50 EUR to Bahn Reisen GmbH",
+				[2]string{"Transaction ID", "1AB23456GH"},
+				[2]string{"Transaction date", "Aug 30, 2025"},
+				[2]string{"Funding sources used", "Visa Credit Card x-1234 $27.91 USD"},
+			),
+			expected: &ledger.Transaction{
+				Account: "assets:paypal",
+				Status:  ledger.Cleared,
+				Payee:   "Bahn Reisen GmbH",
+				Amount:  "$27.91",
+				ID:      "1AB23456GH",
+				Date:    time.Date(2025, 8, 30, 0, 0, 0, 0, time.UTC),
+				Tags:    map[string]string{"funding": "Visa Credit Card x-1234"},
+				Postings: []ledger.Posting{
+					{Account: "assets:paypal", Amount: "-$27.91"},
+					{Account: "e.FIXME", Amount: "25.50 EUR", Cost: "$27.91", TotalCost: true},
+				},
+			},
+		},
+		{
+			name: "foreign payment received with conversion rate and fee",
+			message: receiptEmail("Hans Müller sent you €100.00 EUR",
+				[2]string{"Transaction ID", "5CD67890IJ"},
+				[2]string{"Transaction date", "Sep 1, 2025"},
+				[2]string{"Conversion rate", "1 EUR = 1.0945 USD"},
+				[2]string{"Fee", "$4.20 USD"},
+			),
+			expected: &ledger.Transaction{
+				Account:   "assets:paypal",
+				Status:    ledger.Cleared,
+				Payee:     "Hans Müller",
+				Amount:    "$105.25",
+				IsReceive: true,
+				ID:        "5CD67890IJ",
+				Date:      time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+				Tags:      map[string]string{"fee": "$4.20"},
+				Postings: []ledger.Posting{
+					{Account: "assets:paypal", Amount: "$105.25"},
+					{Account: "expenses:fees:paypal", Amount: "$4.20"},
+					{Account: "e.FIXME", Amount: "-100.00 EUR", Cost: "$1.0945"},
+				},
+			},
+		},
+		{
+			name: "foreign payment received with net amount",
+			message: receiptEmail("Hans Müller sent you €100.00 EUR",
+				[2]string{"Transaction ID", "5CD67890IJ"},
+				[2]string{"Transaction date", "Sep 1, 2025"},
+				[2]string{"Conversion rate", "1 EUR = 1.0945 USD"},
+				[2]string{"Fee", "$4.20 USD"},
+				[2]string{"Net amount", "$105.25 USD"},
+			),
+			expected: &ledger.Transaction{
+				Account:   "assets:paypal",
+				Status:    ledger.Cleared,
+				Payee:     "Hans Müller",
+				Amount:    "$105.25",
+				IsReceive: true,
+				ID:        "5CD67890IJ",
+				Date:      time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+				Tags:      map[string]string{"fee": "$4.20"},
+				Postings: []ledger.Posting{
+					{Account: "assets:paypal", Amount: "$105.25"},
+					{Account: "expenses:fees:paypal", Amount: "$4.20"},
+					{Account: "e.FIXME", Amount: "-100.00 EUR", Cost: "$1.0945"},
+				},
+			},
+		},
+		{
+			name: "net amount without conversion rate",
+			message: receiptEmail("Hans Müller sent you €100.00 EUR",
+				[2]string{"Transaction ID", "5CD67890IJ"},
+				[2]string{"Transaction date", "Sep 1, 2025"},
+				[2]string{"Fee", "$4.20 USD"},
+				[2]string{"Net amount", "$105.25 USD"},
+			),
+			expected: &ledger.Transaction{
+				Account:   "assets:paypal",
+				Status:    ledger.Cleared,
+				Payee:     "Hans Müller",
+				Amount:    "$105.25",
+				IsReceive: true,
+				ID:        "5CD67890IJ",
+				Date:      time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+				Tags:      map[string]string{"fee": "$4.20"},
+				Postings: []ledger.Posting{
+					{Account: "assets:paypal", Amount: "$105.25"},
+					{Account: "expenses:fees:paypal", Amount: "$4.20"},
+					{Account: "e.FIXME", Amount: "-100.00 EUR", Cost: "$109.45", TotalCost: true},
+				},
+			},
+		},
+		{
+			name: "refund in body without date",
+			message: receiptEmail("Book Store sent you a refund of $12.00 USD",
+				[2]string{"Transaction ID", "9RF12345AB"},
+				[2]string{"Original transaction ID", "4PX98765CD"},
+			),
+			expected: &ledger.Transaction{
+				Account:   "assets:paypal",
+				Status:    ledger.Cleared,
+				Payee:     "Book Store",
+				Amount:    "$12.00",
+				IsReceive: true,
+				ID:        "9RF12345AB",
+				Ref:       "4PX98765CD",
+				Date:      time.Date(2025, 9, 2, 10, 58, 36, 0, time.FixedZone("", -7*60*60)),
+			},
+		},
+		{
+			name: "unrelated",
+			message: &gmail.Message{
+				Snippet: "Your monthly account statement is available",
+				Payload: &gmail.MessagePart{},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ProviderPaypal{Account: "assets:paypal"}
+			result, err := p.GetTransaction(tc.message)
+			assert.NoError(t, err)
+			if tc.expected == nil {
+				assert.Nil(t, result)
+				return
+			}
+			assert.Equal(t, tc.expected.Date.Unix(), result.Date.Unix())
+			result.Date = tc.expected.Date
+			assert.Equal(t, tc.expected, result)
+			assert.NoError(t, result.Check())
+		})
+	}
+}
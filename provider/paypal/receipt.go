@@ -0,0 +1,304 @@
+package paypal
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	htmlparser "golang.org/x/net/html"
+	"google.golang.org/api/gmail/v1"
+)
+
+// amount as shown in PayPal receipts, "$1,234.56 USD" or "€12.00 EUR"
+const amt = `(?P<amt>[^\d\s-]{0,3}\d{1,3}(?:,\d{3})*\.\d{2})\s+(?P<cur>[A-Z]{3})`
+
+// headline is the sentence at the top of a receipt describing the payment.
+type headline struct {
+	exp     *regexp.Regexp
+	receive bool
+}
+
+var (
+	headlines []headline
+	expAmount *regexp.Regexp
+	expRate   *regexp.Regexp
+	expSpaces *regexp.Regexp
+)
+
+func init() {
+	headlines = []headline{
+		{exp: regexp.MustCompile(`^(?P<payee>.+?) (?:sent|issued) you a refund of ` + amt), receive: true},
+		{exp: regexp.MustCompile(`^You received a refund of ` + amt + ` from (?P<payee>.+?)\.?$`), receive: true},
+		{exp: regexp.MustCompile(`^You sent ` + amt + ` to (?P<payee>.+?)\.?$`)},
+		{exp: regexp.MustCompile(`^You (?:paid|sent a payment of) ` + amt + ` to (?P<payee>.+?)\.?$`)},
+		{exp: regexp.MustCompile(`^(?P<payee>.+?) sent you ` + amt), receive: true},
+	}
+	expAmount = regexp.MustCompile(amt)
+	expRate = regexp.MustCompile(`1 ([A-Z]{3}) = (\d+(?:\.\d+)?) ([A-Z]{3})`)
+	expSpaces = regexp.MustCompile(`[ \t\p{Zs}]+`)
+}
+
+// receipt holds the details of a PayPal receipt email.
+type receipt struct {
+	payee   string
+	amount  string
+	receive bool
+	note    string
+	id      string
+	ref     string
+	date    time.Time
+	fee     string
+	funding string
+	// rate is the cost of one unit of the payment's currency
+	rate string
+	// converted is the amount in the PayPal account's currency
+	converted string
+	// net is set when converted already has the fee taken out
+	net bool
+}
+
+// parseReceipt reads the HTML body of a PayPal receipt. It returns nil when
+// the message has no HTML body or it isn't a payment receipt.
+func parseReceipt(msg *gmail.Message) (*receipt, error) {
+	body, err := email.HTML(msg)
+	if err != nil || body == nil {
+		return nil, err
+	}
+	lines := htmlToLines(string(body))
+
+	var r receipt
+	found := false
+find:
+	for _, l := range lines {
+		for _, h := range headlines {
+			m := h.exp.FindStringSubmatch(l)
+			if len(m) == 0 {
+				continue
+			}
+			result := make(map[string]string)
+			for i, name := range h.exp.SubexpNames() {
+				if i != 0 && name != "" {
+					result[name] = strings.TrimSpace(m[i])
+				}
+			}
+			r.payee = result["payee"]
+			r.amount = normalizeAmount(result["amt"], result["cur"])
+			r.receive = h.receive
+			found = true
+			break find
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+
+	r.ref = field(lines, "Original transaction ID")
+	r.id = field(lines, "Transaction ID", "Refund ID")
+	r.note = field(lines, "Note to "+r.payee, "Your note to "+r.payee, "Note from "+r.payee, "Message from "+r.payee, "Note", "Message")
+	if fee := expAmount.FindStringSubmatch(field(lines, "Fee", "PayPal fee", "Transaction fee")); len(fee) != 0 {
+		r.fee = normalizeAmount(fee[1], fee[2])
+	}
+	funding := field(lines, "Paid with", "Funding source", "Funding sources used", "Payment method")
+	r.funding = strings.TrimSpace(expAmount.ReplaceAllString(funding, ""))
+	if m := expRate.FindStringSubmatch(field(lines, "Conversion rate", "Exchange rate", "Currency conversion")); len(m) != 0 {
+		r.rate = normalizeAmount(currencySymbol(m[3])+m[2], m[3])
+	}
+	converted := funding
+	if r.receive {
+		if converted = field(lines, "Net amount"); converted != "" {
+			r.net = true
+		} else {
+			converted = field(lines, "Amount received", "Converted amount")
+		}
+	}
+	if m := expAmount.FindStringSubmatch(converted); len(m) != 0 {
+		r.converted = normalizeAmount(m[1], m[2])
+	}
+	if ds := field(lines, "Transaction date", "Date"); ds != "" {
+		for _, layout := range []string{"Jan 2, 2006", "January 2, 2006", "Jan 2, 2006 15:04:05 MST", "January 2, 2006 15:04:05 MST", "2006-01-02"} {
+			if d, err := time.Parse(layout, ds); err == nil {
+				r.date = d
+				break
+			}
+		}
+	}
+	return &r, nil
+}
+
+// transaction converts the receipt into a transaction on account. Fees and
+// payments in another currency are written as separate postings: the fee to
+// feeAccount and the payment itself, at its conversion rate, against the
+// uncategorised account.
+func (r *receipt) transaction(account, feeAccount string) (ledger.Transaction, error) {
+	t := ledger.Transaction{
+		Account:   account,
+		Payee:     r.payee,
+		Amount:    r.amount,
+		IsReceive: r.receive,
+		Note:      r.note,
+		ID:        r.id,
+		Ref:       r.ref,
+		Date:      r.date,
+		// PayPal only notifies about completed payments
+		Status: ledger.Cleared,
+	}
+	if r.fee != "" || r.funding != "" {
+		t.Tags = make(map[string]string)
+	}
+	if r.fee != "" {
+		t.Tags["fee"] = r.fee
+	}
+	if r.funding != "" {
+		t.Tags["funding"] = r.funding
+	}
+
+	gross, err := ledger.ParseAmount(r.amount)
+	if err != nil {
+		return t, err
+	}
+	foreign := gross.Commodity != "$"
+	if !foreign && r.fee == "" {
+		return t, nil
+	}
+	if !r.receive {
+		gross = gross.Neg()
+	}
+
+	// net is what the PayPal account gains or loses: the payment, converted
+	// if need be, less the fee.
+	net := gross
+	known := !foreign
+	deducted := false
+	if foreign && r.converted != "" {
+		if net, err = ledger.ParseAmount(r.converted); err != nil {
+			return t, err
+		}
+		if !r.receive {
+			net = net.Neg()
+		}
+		known = true
+		deducted = r.net
+	}
+	// converted is the payment in the account's currency before the fee
+	converted := net
+	primary := ledger.Posting{Account: account}
+	var fee ledger.Posting
+	if r.fee != "" {
+		f, err := ledger.ParseAmount(r.fee)
+		if err != nil {
+			return t, err
+		}
+		if deducted {
+			if c, err := net.Add(f); err == nil {
+				converted = c
+			}
+		} else if net, err = net.Sub(f); err != nil {
+			known = false
+		}
+		fee = ledger.Posting{Account: feeAccount, Amount: f.String()}
+	}
+	if known {
+		primary.Amount = net.String()
+		t.Amount = net.Abs().String()
+		t.IsReceive = net.Quantity > 0
+	}
+
+	counter := ledger.Posting{Account: ledger.Uncategorised, Amount: gross.Neg().String()}
+	if foreign {
+		switch {
+		case r.rate != "":
+			counter.Cost = r.rate
+		case r.converted != "":
+			counter.Cost, counter.TotalCost = converted.Abs().String(), true
+		}
+	}
+	t.Postings = []ledger.Posting{primary}
+	if fee.Account != "" {
+		t.Postings = append(t.Postings, fee)
+	}
+	t.Postings = append(t.Postings, counter)
+	if !known && counter.Cost != "" {
+		// the conversion rate gives the net in the account's currency
+		if err := t.AutoBalance(); err != nil {
+			return t, err
+		}
+		a, err := ledger.ParseAmount(t.Postings[0].Amount)
+		if err != nil {
+			return t, err
+		}
+		t.Amount = a.Abs().String()
+		t.IsReceive = a.Quantity > 0
+	}
+	return t, nil
+}
+
+func currencySymbol(cur string) string {
+	if cur == "USD" {
+		return "$"
+	}
+	return ""
+}
+
+// normalizeAmount keeps US dollars as "$12.00" and writes other currencies
+// with their code, "12.00 EUR", since "$" alone is ambiguous.
+func normalizeAmount(a, cur string) string {
+	if cur == "USD" || cur == "" {
+		return a
+	}
+	return strings.TrimLeft(a, "$€£¥") + " " + cur
+}
+
+// field returns the value for the first of labels found in lines, either on
+// the same line as "Label: value" or on the line following the label.
+func field(lines []string, labels ...string) string {
+	for _, label := range labels {
+		for i, l := range lines {
+			if strings.EqualFold(l, label) || strings.EqualFold(l, label+":") {
+				if i+1 < len(lines) {
+					return lines[i+1]
+				}
+				continue
+			}
+			if len(l) > len(label)+1 && strings.EqualFold(l[:len(label)+1], label+":") {
+				return strings.TrimSpace(l[len(label)+1:])
+			}
+		}
+	}
+	return ""
+}
+
+// htmlToLines returns the non-empty lines of text in an HTML document, one
+// per block element.
+func htmlToLines(s string) []string {
+	var b strings.Builder
+	tokenizer := htmlparser.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := tokenizer.Next()
+		switch tt {
+		case htmlparser.ErrorToken:
+			var lines []string
+			for _, l := range strings.Split(b.String(), "\n") {
+				l = strings.TrimSpace(expSpaces.ReplaceAllString(html.UnescapeString(l), " "))
+				if l != "" {
+					lines = append(lines, l)
+				}
+			}
+			return lines
+		case htmlparser.TextToken:
+			b.WriteString(strings.ReplaceAll(string(tokenizer.Text()), "\n", " "))
+		case htmlparser.StartTagToken, htmlparser.EndTagToken, htmlparser.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "style", "script":
+				if tt == htmlparser.StartTagToken {
+					tokenizer.Next()
+				}
+			case "br", "p", "div", "li", "tr", "td", "th", "table", "h1", "h2", "h3", "h4", "h5", "h6":
+				b.WriteString("\n")
+			}
+		}
+	}
+}
@@ -1,21 +1,71 @@
 package provider
 
 import (
+	"fmt"
+
+	"github.com/mikelu92/emailimport/pkg/email"
 	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/payroll"
+	"github.com/mikelu92/emailimport/pkg/receipt"
+	"github.com/mikelu92/emailimport/pkg/trade"
+	"github.com/mikelu92/emailimport/provider/adp"
 	"github.com/mikelu92/emailimport/provider/affinity"
+	"github.com/mikelu92/emailimport/provider/amazon"
+	"github.com/mikelu92/emailimport/provider/amex"
+	"github.com/mikelu92/emailimport/provider/bill"
+	"github.com/mikelu92/emailimport/provider/bofa"
 	"github.com/mikelu92/emailimport/provider/capitalone"
+	"github.com/mikelu92/emailimport/provider/cashapp"
 	"github.com/mikelu92/emailimport/provider/chase"
+	"github.com/mikelu92/emailimport/provider/citi"
+	"github.com/mikelu92/emailimport/provider/coinbase"
+	"github.com/mikelu92/emailimport/provider/deposit"
 	"github.com/mikelu92/emailimport/provider/discover"
+	"github.com/mikelu92/emailimport/provider/doordash"
+	"github.com/mikelu92/emailimport/provider/fidelity"
+	"github.com/mikelu92/emailimport/provider/gusto"
 	"github.com/mikelu92/emailimport/provider/paypal"
+	"github.com/mikelu92/emailimport/provider/schwab"
+	"github.com/mikelu92/emailimport/provider/statement"
 	"github.com/mikelu92/emailimport/provider/target"
+	"github.com/mikelu92/emailimport/provider/uber"
+	"github.com/mikelu92/emailimport/provider/venmo"
+	"github.com/mikelu92/emailimport/provider/wellsfargo"
+	"github.com/mikelu92/emailimport/provider/zelle"
 	"google.golang.org/api/gmail/v1"
 )
 
 type ProviderConfig struct {
-	Account  string
-	Accounts map[int]string
-	Label    string
-	Type     string
+	Account string
+	// Accounts routes transactions by the last four digits of the card in
+	// the email. Account is used for emails without one, and for unknown
+	// suffixes unless StrictAccounts makes those an error.
+	Accounts       map[int]string
+	StrictAccounts bool
+	// DateSource picks the date of transactions: transaction (default), the
+	// date in the email body when it has one, sent, the Date header, or
+	// received, when the email reached the mail server.
+	DateSource string
+	FeeAccount string
+	// TaxAccount and TipAccount receive the tax and tip of itemised
+	// receipts, see the receipt package for their defaults. TaxAccount also
+	// receives the taxes withheld from pay stubs, along with IncomeAccount
+	// and DeductionAccount, see the payroll package.
+	TaxAccount       string
+	TipAccount       string
+	IncomeAccount    string
+	DeductionAccount string
+	// CashbackAccount receives Discover Cashback Bonus redemptions, see
+	// discover.DefaultCashbackAccount.
+	CashbackAccount string
+	// CashAccount pays for trades and receives the proceeds of sales, Account
+	// by default, which holds a sub-account per symbol traded.
+	CashAccount string
+	// Invert reads the amounts of attached statements with charges positive,
+	// as card statements write them.
+	Invert bool
+	Label  string
+	Type   string
 }
 
 type Provider interface {
@@ -23,21 +73,133 @@ type Provider interface {
 	GetAccount() string
 }
 
+// StatementProvider is a Provider reading every transaction of a statement
+// attached to the email, which must be fetched first, see
+// email.FetchAttachments.
+type StatementProvider interface {
+	Provider
+	GetTransactions(msg *gmail.Message) ([]ledger.Transaction, error)
+}
+
+// Date sources, see ProviderConfig.DateSource.
+const (
+	DateTransaction = "transaction"
+	DateSent        = "sent"
+	DateReceived    = "received"
+)
+
+// Validate reports configuration errors that Get would otherwise ignore.
+func (conf ProviderConfig) Validate() error {
+	if get(conf) == nil {
+		return fmt.Errorf("unknown provider type %q", conf.Type)
+	}
+	switch conf.DateSource {
+	case "", DateTransaction, DateSent, DateReceived:
+	default:
+		return fmt.Errorf("provider %s: unknown date source %q", conf.Type, conf.DateSource)
+	}
+	return nil
+}
+
 func Get(conf ProviderConfig) Provider {
+	p := get(conf)
+	if p == nil {
+		return nil
+	}
+	// statement lines have their own dates and are all in the account
+	if _, ok := p.(StatementProvider); ok {
+		return p
+	}
+	if conf.Type != "chase" && len(conf.Accounts) != 0 {
+		p = &routed{
+			Provider: p,
+			router:   email.Router{Accounts: conf.Accounts, Default: conf.Account, Strict: conf.StrictAccounts},
+		}
+	}
+	if conf.DateSource == DateSent || conf.DateSource == DateReceived {
+		p = &dated{Provider: p, source: conf.DateSource}
+	}
+	return p
+}
+
+func get(conf ProviderConfig) Provider {
 	switch conf.Type {
 	case "paypal":
-		return &paypal.ProviderPaypal{Account: conf.Account}
+		return &paypal.ProviderPaypal{Account: conf.Account, FeeAccount: conf.FeeAccount}
 	case "discover":
-		return &discover.ProviderDiscover{Account: conf.Account}
+		return &discover.ProviderDiscover{Account: conf.Account, CashbackAccount: conf.CashbackAccount}
 	case "target":
 		return &target.ProviderTarget{Account: conf.Account}
 	case "affinity":
 		return &affinity.ProviderAffinity{Account: conf.Account}
 	case "chase":
-		return &chase.ProviderChase{Accounts: conf.Accounts}
+		return &chase.ProviderChase{Accounts: conf.Accounts, Account: conf.Account, Strict: conf.StrictAccounts}
 	case "capitalone":
 		return &capitalone.ProviderCapitalOne{Account: conf.Account}
+	case "amex":
+		return &amex.ProviderAmex{Account: conf.Account}
+	case "citi":
+		return &citi.ProviderCiti{Account: conf.Account}
+	case "bofa":
+		return &bofa.ProviderBofA{Account: conf.Account}
+	case "wellsfargo":
+		return &wellsfargo.ProviderWellsFargo{Account: conf.Account}
+	case "venmo":
+		return &venmo.ProviderVenmo{Account: conf.Account}
+	case "zelle":
+		return &zelle.ProviderZelle{Account: conf.Account}
+	case "cashapp":
+		return &cashapp.ProviderCashApp{Account: conf.Account}
+	case "amazon":
+		return &amazon.ProviderAmazon{Account: conf.Account, Accounts: conf.receiptAccounts()}
+	case "uber":
+		return &uber.ProviderUber{Account: conf.Account, Accounts: conf.receiptAccounts()}
+	case "doordash":
+		return &doordash.ProviderDoorDash{Account: conf.Account, Accounts: conf.receiptAccounts()}
+	case "adp":
+		return &adp.ProviderADP{Account: conf.Account, Accounts: conf.payrollAccounts()}
+	case "gusto":
+		return &gusto.ProviderGusto{Account: conf.Account, Accounts: conf.payrollAccounts()}
+	case "deposit":
+		return &deposit.ProviderDeposit{Account: conf.Account}
+	case "fidelity":
+		return &fidelity.ProviderFidelity{Accounts: conf.tradeAccounts()}
+	case "schwab":
+		return &schwab.ProviderSchwab{Accounts: conf.tradeAccounts()}
+	case "coinbase":
+		return &coinbase.ProviderCoinbase{Accounts: conf.tradeAccounts()}
+	case "bill":
+		return &bill.ProviderBill{Account: conf.Account}
+	case "statement":
+		return &statement.ProviderStatement{Account: conf.Account, Invert: conf.Invert}
 
 	}
 	return nil
 }
+
+// IsReceipt reports whether providers of type typ read itemised receipts
+// or pay stubs rather than the charges or deposits paying them, see
+// receipt.Matcher.
+func IsReceipt(typ string) bool {
+	switch typ {
+	case "amazon", "uber", "doordash", "adp", "gusto":
+		return true
+	}
+	return false
+}
+
+func (conf ProviderConfig) receiptAccounts() receipt.Accounts {
+	return receipt.Accounts{Tax: conf.TaxAccount, Tip: conf.TipAccount, Fee: conf.FeeAccount}
+}
+
+func (conf ProviderConfig) payrollAccounts() payroll.Accounts {
+	return payroll.Accounts{Income: conf.IncomeAccount, Tax: conf.TaxAccount, Deduction: conf.DeductionAccount}
+}
+
+func (conf ProviderConfig) tradeAccounts() trade.Accounts {
+	cash := conf.CashAccount
+	if cash == "" {
+		cash = conf.Account
+	}
+	return trade.Accounts{Holdings: conf.Account, Cash: cash, Fee: conf.FeeAccount}
+}
@@ -0,0 +1,17 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/mikelu92/emailimport/provider/discover"
+)
+
+func TestGetPassesCashbackAccount(t *testing.T) {
+	p, ok := Get(ProviderConfig{Type: "discover", Account: "liabilities:discover", CashbackAccount: "income:rewards"}).(*discover.ProviderDiscover)
+	if !ok {
+		t.Fatalf("expected a Discover provider")
+	}
+	if p.CashbackAccount != "income:rewards" {
+		t.Fatalf("expected cashback account income:rewards, got %q", p.CashbackAccount)
+	}
+}
@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+// routed moves the transactions of a single account provider to the account
+// of the card suffix found anywhere in the email.
+type routed struct {
+	Provider
+	router email.Router
+}
+
+func (r *routed) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	t, err := r.Provider.GetTransaction(msg)
+	if err != nil || t == nil {
+		return t, err
+	}
+	act, err := r.router.Route(email.Text(msg))
+	if err != nil {
+		return nil, err
+	}
+	if act == "" {
+		return nil, nil
+	}
+	for i := range t.Postings {
+		if t.Postings[i].Account == t.Account {
+			t.Postings[i].Account = act
+		}
+	}
+	t.Account = act
+	return t, nil
+}
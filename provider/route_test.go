@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"google.golang.org/api/gmail/v1"
+)
+
+func capitalOneAlert(suffix string) *gmail.Message {
+	body := "About your Venture X Card ending in " + suffix + "\n\n" +
+		"As requested, we're notifying you that on April 16, 2025, at Grocery Store, a pending authorization or purchase in the amount of $22.43 was placed or charged on your Venture X Card."
+	return &gmail.Message{
+		Payload: &gmail.MessagePart{
+			Headers: []*gmail.MessagePartHeader{
+				{Name: "Subject", Value: "A new transaction was charged to your account"},
+				{Name: "Content-Type", Value: "text/plain; charset=\"UTF-8\""},
+			},
+			Body: &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(body))},
+		},
+	}
+}
+
+func TestGetRoutesByCardSuffix(t *testing.T) {
+	conf := ProviderConfig{
+		Type:     "capitalone",
+		Account:  "liabilities:capitalone",
+		Accounts: map[int]string{1807: "liabilities:venture", 4242: "liabilities:quicksilver"},
+	}
+	tests := []struct {
+		suffix string
+		want   string
+	}{
+		{suffix: "1807", want: "liabilities:venture"},
+		{suffix: "4242", want: "liabilities:quicksilver"},
+		{suffix: "9999", want: "liabilities:capitalone"},
+	}
+	for _, tt := range tests {
+		tx, err := Get(conf).GetTransaction(capitalOneAlert(tt.suffix))
+		if err != nil {
+			t.Fatalf("GetTransaction returned error: %v", err)
+		}
+		if tx == nil || tx.Account != tt.want {
+			t.Fatalf("suffix %s: expected account %q, got %+v", tt.suffix, tt.want, tx)
+		}
+	}
+
+	conf.StrictAccounts = true
+	if _, err := Get(conf).GetTransaction(capitalOneAlert("9999")); !errors.Is(err, email.ErrUnknownSuffix) {
+		t.Fatalf("expected ErrUnknownSuffix, got %v", err)
+	}
+
+	conf.Account, conf.StrictAccounts = "", false
+	tx, err := Get(conf).GetTransaction(capitalOneAlert("9999"))
+	if err != nil || tx != nil {
+		t.Fatalf("expected unknown suffix to be skipped without a default account, got %+v, %v", tx, err)
+	}
+}
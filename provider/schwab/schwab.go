@@ -0,0 +1,66 @@
+package schwab
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/trade"
+	"google.golang.org/api/gmail/v1"
+)
+
+var (
+	// "Bought 10 VTI @ $220.00", "Sold 2 BRK.B @ 451.2300"; the price is
+	// sometimes written without a dollar sign
+	expTrade     = regexp.MustCompile(`\b(?P<side>Bought|Sold|BOUGHT|SOLD) (?P<qty>\d{1,3}(?:,\d{3})*(?:\.\d+)?) (?i:shares? of )?(?P<sym>[A-Z][A-Z0-9./]*) @ \$?(?P<price>\d{1,3}(?:,\d{3})*\.\d+)`)
+	expFee       = regexp.MustCompile(`(?i)(?:commission|fees?):?\s*\$(\d{1,3}(?:,\d{3})*\.\d{2})`)
+	expOrder     = regexp.MustCompile(`(?i)order (?:number|#|id):?\s*(\d{5,})`)
+	expTradeDate = regexp.MustCompile(`(?i)trade date:?\s*(\d{2}/\d{2}/\d{4})`)
+)
+
+// ProviderSchwab reads Charles Schwab trade confirmations into lots held in
+// a sub-account per symbol of Accounts.Holdings.
+type ProviderSchwab struct {
+	Accounts trade.Accounts
+}
+
+func (p *ProviderSchwab) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	text := email.Text(msg)
+	m := expTrade.FindStringSubmatch(text)
+	if len(m) == 0 {
+		return nil, nil
+	}
+	tr := trade.Trade{
+		Broker:   "Schwab",
+		Sell:     m[1] == "Sold" || m[1] == "SOLD",
+		Quantity: m[2],
+		Symbol:   m[3],
+		Price:    "$" + m[4],
+	}
+	if f := expFee.FindStringSubmatch(text); len(f) != 0 && f[1] != "0.00" {
+		tr.Fee = "$" + f[1]
+	}
+	if o := expOrder.FindStringSubmatch(text); len(o) != 0 {
+		tr.ID = o[1]
+	}
+	tr.Date = email.Sent(msg)
+	if d := expTradeDate.FindStringSubmatch(text); len(d) != 0 {
+		if date, err := time.Parse("01/02/2006", d[1]); err == nil {
+			tr.Date = date
+		}
+	}
+	if tr.Date.IsZero() {
+		return nil, errors.New("schwab: email has no date")
+	}
+	t, err := tr.Transaction(p.Accounts)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (p *ProviderSchwab) GetAccount() string {
+	return p.Accounts.Cash
+}
@@ -0,0 +1,39 @@
+package schwab
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/trade"
+	"github.com/stretchr/testify/assert"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Mon, 5 May 2025 14:31:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	p := &ProviderSchwab{Accounts: trade.Accounts{Holdings: "assets:schwab", Cash: "assets:schwab"}}
+	tx, err := p.GetTransaction(emailtest.Text(dateHeader, "Trade Confirmation", `Your order has been filled.
+Sold 2 BRK.B @ 451.2300
+Commission & Fees: $0.00
+Order #: 1234567890
+Trade Date: 05/02/2025`))
+	assert.NoError(t, err)
+	assert.Equal(t, &ledger.Transaction{
+		ID: "1234567890", Status: ledger.Cleared, Payee: "Schwab Sell BRK.B", Amount: "$902.46", IsReceive: true,
+		Date: time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC), Account: "assets:schwab",
+		Postings: []ledger.Posting{
+			{Account: "assets:schwab:BRK.B", Amount: `-2 "BRK.B"`, Cost: "$451.2300"},
+			{Account: "assets:schwab", Amount: "$902.46"},
+		},
+	}, tx)
+
+	tx, err = p.GetTransaction(emailtest.Text(dateHeader, "Trade Confirmation", "Bought 15.5 SCHD @ $27.10\nCommission: $0.00"))
+	assert.NoError(t, err)
+	assert.Equal(t, []ledger.Posting{
+		{Account: "assets:schwab:SCHD", Amount: "15.5 SCHD", Cost: "$27.10"},
+		{Account: "assets:schwab", Amount: "-$420.05"},
+	}, tx.Postings)
+}
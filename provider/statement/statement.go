@@ -0,0 +1,57 @@
+package statement
+
+import (
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	stmt "github.com/mikelu92/emailimport/pkg/statement"
+	"google.golang.org/api/gmail/v1"
+)
+
+// ProviderStatement reads every line of the statements attached to an email
+// as a cleared transaction in Account. Invert is for card statements, which
+// write charges as positive amounts.
+type ProviderStatement struct {
+	Account string
+	Invert  bool
+	// Parsers read the attachments, stmt.Parsers when empty.
+	Parsers []stmt.Parser
+}
+
+// GetTransaction returns the first line of the statement, see
+// GetTransactions.
+func (p *ProviderStatement) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	ts, err := p.GetTransactions(msg)
+	if err != nil || len(ts) == 0 {
+		return nil, err
+	}
+	return &ts[0], nil
+}
+
+// GetTransactions returns the transactions of every line of the statements
+// attached to msg, or none when no attachment is a statement.
+func (p *ProviderStatement) GetTransactions(msg *gmail.Message) ([]ledger.Transaction, error) {
+	parsers := p.Parsers
+	if len(parsers) == 0 {
+		parsers = stmt.Parsers
+	}
+	lines, ok, err := stmt.Parse(email.Attachments(msg), email.Sent(msg), parsers)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, email.ErrNoTransaction
+	}
+	ts := make([]ledger.Transaction, 0, len(lines))
+	for _, l := range lines {
+		t, err := l.Transaction(p.Account, p.Invert)
+		if err != nil {
+			return nil, err
+		}
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
+func (p *ProviderStatement) GetAccount() string {
+	return p.Account
+}
@@ -0,0 +1,60 @@
+package statement
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+func message(filename, mimeType, data string) *gmail.Message {
+	return &gmail.Message{
+		Payload: &gmail.MessagePart{
+			MimeType: "multipart/mixed",
+			Headers:  []*gmail.MessagePartHeader{{Name: "Date", Value: "Mon, 3 Feb 2025 09:50:14 +0000"}},
+			Parts: []*gmail.MessagePart{
+				{MimeType: "text/plain", Body: &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte("Your January statement is attached."))}},
+				{MimeType: mimeType, Filename: filename, Body: &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(data))}},
+			},
+		},
+	}
+}
+
+func TestGetTransactions(t *testing.T) {
+	msg := message("Activity.csv", "text/csv", "Date,Description,Amount\n"+
+		"01/15/2025,AMAZON MKTPLACE,12.34\n"+
+		"01/20/2025,ONLINE PAYMENT,-500.00\n")
+	p := &ProviderStatement{Account: "liabilities:card", Invert: true}
+	ts, err := p.GetTransactions(msg)
+	if err != nil {
+		t.Fatalf("GetTransactions returned error: %v", err)
+	}
+	if len(ts) != 2 {
+		t.Fatalf("expected 2 transactions, got %+v", ts)
+	}
+	if ts[0].Payee != "AMAZON MKTPLACE" || ts[0].Amount != "$12.34" || ts[0].IsReceive {
+		t.Fatalf("expected charge of $12.34, got %+v", ts[0])
+	}
+	if ts[1].Payee != "ONLINE PAYMENT" || ts[1].Amount != "$500.00" || !ts[1].IsReceive {
+		t.Fatalf("expected payment of $500.00, got %+v", ts[1])
+	}
+	for _, tx := range ts {
+		if tx.Status != ledger.Cleared || tx.Account != "liabilities:card" {
+			t.Fatalf("expected cleared transaction in liabilities:card, got %+v", tx)
+		}
+	}
+}
+
+func TestGetTransactionsNoStatement(t *testing.T) {
+	p := &ProviderStatement{Account: "assets:checking"}
+	if ts, err := p.GetTransactions(message("logo.png", "image/png", "png")); err != nil || ts != nil {
+		t.Fatalf("expected no transactions, got %+v, %v", ts, err)
+	}
+	_, err := p.GetTransactions(message("Activity.csv", "text/csv", "Date,Description,Amount\n"))
+	if !errors.Is(err, email.ErrNoTransaction) {
+		t.Fatalf("expected ErrNoTransaction for an empty statement, got %v", err)
+	}
+}
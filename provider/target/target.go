@@ -1,87 +1,81 @@
 package target
 
 import (
-	"encoding/base64"
+	"errors"
 	"regexp"
 	"strings"
-	"time"
 
+	"github.com/mikelu92/emailimport/pkg/email"
 	"github.com/mikelu92/emailimport/pkg/ledger"
 	"google.golang.org/api/gmail/v1"
 )
 
-var exp *regexp.Regexp
+const (
+	amt = `(?P<amt>\$\d{1,3}(?:,\d{3})*\.\d{2})`
+	// the Target Circle credit and debit cards, Target Mastercard and the
+	// RedCard names they replaced
+	card = `(?P<card>(?:Target )?(?:Circle™?|RedCard™?)?(?: (?:Debit|Credit))? ?(?:Card|Mastercard®?))`
+)
+
+// alert is a Target card email, recognised by a sentence of its body.
+type alert struct {
+	exp     *regexp.Regexp
+	receive bool
+	status  ledger.Status
+	payee   string
+	// declined alerts don't move any money
+	declined bool
+}
+
+var alerts []alert
 
 func init() {
-	exp = regexp.MustCompile("(?s)Hello .*,.*A transaction of (?P<amt>\\$\\d+\\.\\d+)[\\s\\p{Zs}]+at[\\s\\p{Zs}]+(?P<payee>.+?)[\\s\\p{Zs}]+has been approved on your.*Target Circle.*Card")
+	alerts = []alert{
+		{exp: regexp.MustCompile(`(?i)A (?:transaction|purchase) of ` + amt + ` at (?P<payee>.+?) (?:has been|was) declined on your ` + card), declined: true},
+		{exp: regexp.MustCompile(`(?i)(?:A (?:credit|refund|return) of|The transaction of) ` + amt + ` (?:from|at) (?P<payee>.+?) has been (?:posted to|applied to|credited to|reversed on) your ` + card), receive: true, status: ledger.Cleared},
+		{exp: regexp.MustCompile(`(?i)(?:(?:A|Your) payment of ` + amt + ` (?:has been|was) (?:received|posted|applied|credited)(?: to| on| for)? your ` + card + `|We(?:'ve| have)? received your payment of (?P<amt2>\$\d{1,3}(?:,\d{3})*\.\d{2}))`), receive: true, status: ledger.Cleared, payee: "Target card payment"},
+		// "has been approved" is an authorization, not a posted charge
+		{exp: regexp.MustCompile(`(?i)A (?:transaction|purchase) of ` + amt + ` at (?P<payee>.+?) (?:has been|was) approved on your ` + card), status: ledger.Pending},
+	}
 }
 
 type ProviderTarget struct {
 	Account string
 }
 
-func getBodyText(msg *gmail.Message) string {
-	if msg.Payload == nil {
-		return ""
-	}
-
-	var extractText func(part *gmail.MessagePart) string
-	extractText = func(part *gmail.MessagePart) string {
-		if part == nil {
-			return ""
+func (p *ProviderTarget) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	text := email.Text(msg)
+	for _, a := range alerts {
+		match := a.exp.FindStringSubmatch(text)
+		if len(match) == 0 {
+			continue
 		}
-		if part.MimeType == "text/plain" && part.Body != nil && part.Body.Data != "" {
-			data, err := base64.URLEncoding.DecodeString(part.Body.Data)
-			if err == nil {
-				return string(data)
-			}
+		if a.declined {
+			return nil, email.ErrNoTransaction
 		}
-		if part.MimeType == "text/html" && part.Body != nil && part.Body.Data != "" {
-			data, err := base64.URLEncoding.DecodeString(part.Body.Data)
-			if err == nil {
-				return string(data)
+		t := ledger.Transaction{Account: p.Account, IsReceive: a.receive, Status: a.status, Payee: a.payee}
+		for i, name := range a.exp.SubexpNames() {
+			if match[i] == "" {
+				continue
 			}
-		}
-		for _, child := range part.Parts {
-			if text := extractText(child); text != "" {
-				return text
+			switch strings.TrimSuffix(name, "2") {
+			case "amt":
+				t.Amount = match[i]
+			case "payee":
+				t.Payee = match[i]
 			}
 		}
-		return ""
-	}
-
-	return extractText(msg.Payload)
-}
-
-func (p *ProviderTarget) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
-	t := ledger.Transaction{Account: p.Account}
-	body := getBodyText(msg)
-	match := exp.FindStringSubmatch(body)
-	if len(match) == 0 {
-		return nil, nil
-	}
-	result := make(map[string]string)
-	for i, name := range exp.SubexpNames() {
-		if i != 0 && name != "" {
-			result[name] = match[i]
+		// the alerts don't date the transaction, they're sent right away
+		t.Date = email.Received(msg)
+		if t.Date.IsZero() {
+			t.Date = email.Sent(msg)
 		}
-	}
-	t.Amount = result["amt"]
-	t.Payee = result["payee"]
-	var dateString string
-	for _, h := range msg.Payload.Headers {
-		if h.Name == "Received" {
-			dateString = strings.TrimSpace(strings.Split(h.Value, ";")[1])
-			break
+		if t.Date.IsZero() {
+			return nil, errors.New("target: email has no date")
 		}
+		return &t, nil
 	}
-	d, err := time.Parse("Mon, 2 Jan 2006 15:04:05 -0700 (MST)", dateString)
-	if err != nil {
-		return nil, err
-	}
-	t.Date = d
-
-	return &t, nil
+	return nil, nil
 }
 
 func (p *ProviderTarget) GetAccount() string {
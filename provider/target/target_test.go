@@ -2,9 +2,13 @@ package target
 
 import (
 	"encoding/base64"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
 	"google.golang.org/api/gmail/v1"
 )
 
@@ -130,11 +134,49 @@ func TestGetTransactionSampleEmail(t *testing.T) {
 	if !tx.Date.Equal(expectedDate) {
 		t.Fatalf("expected date %v, got %v", expectedDate, tx.Date)
 	}
+	if tx.Status != ledger.Pending {
+		t.Fatalf("expected pending status, got %q", tx.Status)
+	}
 	if tx.Account != "target:circle" {
 		t.Fatalf("expected account %q, got %q", "target:circle", tx.Account)
 	}
 }
 
+func TestGetTransactionCredit(t *testing.T) {
+	for _, line := range []string{
+		"A credit of $19.99 from TARGET T-1234 has been posted to your Target Circle™ Card.",
+		"The transaction of $19.99 at TARGET T-1234 has been reversed on your Target Circle™ Card.",
+	} {
+		t.Run(line, func(t *testing.T) {
+			email := strings.Replace(exampleEmail, "A transaction of $19.99\u00a0at\u00a0TARGET T-1234 has been approved on your <span class=\"darkMode-text-red\" style=\"color: #cc0000; font-weight: bold;\">Target Circle™ Card</span>.", line, 1)
+			msg := &gmail.Message{
+				Payload: &gmail.MessagePart{
+					Headers: []*gmail.MessagePartHeader{
+						{Name: "Received", Value: "by 2002:a05:7301:3d18:b0:2a4:605a:ae3c with SMTP id oe24csp620026dyb; Fri, 16 Jan 2026 15:17:15 -0800 (PST)"},
+					},
+					MimeType: "text/html",
+					Body:     &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(email))},
+				},
+			}
+
+			p := &ProviderTarget{Account: "target:circle"}
+			tx, err := p.GetTransaction(msg)
+			if err != nil {
+				t.Fatalf("GetTransaction returned error: %v", err)
+			}
+			if tx == nil {
+				t.Fatalf("expected transaction, got nil")
+			}
+			if !tx.IsReceive || tx.Status != ledger.Cleared {
+				t.Fatalf("expected cleared credit, got IsReceive=%t status=%q", tx.IsReceive, tx.Status)
+			}
+			if tx.Amount != "$19.99" || tx.Payee != "TARGET T-1234" {
+				t.Fatalf("unexpected amount %q or payee %q", tx.Amount, tx.Payee)
+			}
+		})
+	}
+}
+
 func TestGetTransactionNoMatch(t *testing.T) {
 	htmlBody := `<html><body><p>This is not a Target email</p></body></html>`
 	encoded := base64.URLEncoding.EncodeToString([]byte(htmlBody))
@@ -156,3 +198,118 @@ func TestGetTransactionNoMatch(t *testing.T) {
 		t.Fatalf("expected nil transaction, got %v", tx)
 	}
 }
+
+func TestGetTransactionAlerts(t *testing.T) {
+	original := "A transaction of $19.99 at TARGET T-1234 has been approved on your <span class=\"darkMode-text-red\" style=\"color: #cc0000; font-weight: bold;\">Target Circle™ Card</span>."
+	tests := []struct {
+		name    string
+		line    string
+		amount  string
+		payee   string
+		receive bool
+		status  ledger.Status
+	}{
+		{
+			name:   "over $999",
+			line:   "A transaction of $1,249.00 at TARGET T-1234 has been approved on your Target Circle™ Card.",
+			amount: "$1,249.00",
+			payee:  "TARGET T-1234",
+			status: ledger.Pending,
+		},
+		{
+			name:   "redcard debit",
+			line:   "A purchase of $52.10 at TARGET T-0987 has been approved on your Target RedCard™ Debit Card.",
+			amount: "$52.10",
+			payee:  "TARGET T-0987",
+			status: ledger.Pending,
+		},
+		{
+			name:   "target mastercard elsewhere",
+			line:   "A transaction of $8.50 at CORNER CAFE was approved on your Target Mastercard®.",
+			amount: "$8.50",
+			payee:  "CORNER CAFE",
+			status: ledger.Pending,
+		},
+		{
+			name:    "payment",
+			line:    "A payment of $2,000.00 has been posted to your Target Circle™ Card.",
+			amount:  "$2,000.00",
+			payee:   "Target card payment",
+			receive: true,
+			status:  ledger.Cleared,
+		},
+		{
+			name:    "payment received",
+			line:    "We've received your payment of $150.00. Thank you!",
+			amount:  "$150.00",
+			payee:   "Target card payment",
+			receive: true,
+			status:  ledger.Cleared,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := strings.Replace(exampleEmail, original, tt.line, 1)
+			msg := &gmail.Message{
+				Payload: &gmail.MessagePart{
+					Headers: []*gmail.MessagePartHeader{
+						{Name: "Received", Value: "by 2002:a05:7301:3d18:b0:2a4:605a:ae3c with SMTP id oe24csp620026dyb; Fri, 16 Jan 2026 15:17:15 -0800 (PST)"},
+					},
+					MimeType: "text/html",
+					Body:     &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(body))},
+				},
+			}
+
+			p := &ProviderTarget{Account: "target:circle"}
+			tx, err := p.GetTransaction(msg)
+			if err != nil {
+				t.Fatalf("GetTransaction returned error: %v", err)
+			}
+			if tx == nil {
+				t.Fatalf("expected transaction, got nil")
+			}
+			if tx.Amount != tt.amount || tx.Payee != tt.payee {
+				t.Fatalf("unexpected amount %q or payee %q", tx.Amount, tx.Payee)
+			}
+			if tx.IsReceive != tt.receive || tx.Status != tt.status {
+				t.Fatalf("unexpected IsReceive=%t status=%q", tx.IsReceive, tx.Status)
+			}
+		})
+	}
+}
+
+func TestGetTransactionDeclined(t *testing.T) {
+	body := strings.Replace(exampleEmail, "has been approved", "has been declined", 1)
+	msg := &gmail.Message{
+		Payload: &gmail.MessagePart{
+			MimeType: "text/html",
+			Body:     &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(body))},
+		},
+	}
+	p := &ProviderTarget{Account: "target:circle"}
+	tx, err := p.GetTransaction(msg)
+	if !errors.Is(err, email.ErrNoTransaction) || tx != nil {
+		t.Fatalf("expected ErrNoTransaction, got %v, %v", tx, err)
+	}
+}
+
+func TestGetTransactionDate(t *testing.T) {
+	encoded := base64.URLEncoding.EncodeToString([]byte(exampleEmail))
+	for _, headers := range [][]*gmail.MessagePartHeader{
+		{{Name: "Received", Value: "by 2002:a05:7301:3d18 with SMTP id oe24csp620026dyb"}},
+		{},
+	} {
+		msg := &gmail.Message{
+			InternalDate: time.Date(2026, 1, 16, 23, 17, 15, 0, time.UTC).UnixMilli(),
+			Payload:      &gmail.MessagePart{Headers: headers, MimeType: "text/html", Body: &gmail.MessagePartBody{Data: encoded}},
+		}
+		p := &ProviderTarget{Account: "target:circle"}
+		tx, err := p.GetTransaction(msg)
+		if err != nil {
+			t.Fatalf("GetTransaction returned error: %v", err)
+		}
+		if tx == nil || !tx.Date.Equal(time.UnixMilli(msg.InternalDate)) {
+			t.Fatalf("expected the Gmail received date, got %+v", tx)
+		}
+	}
+}
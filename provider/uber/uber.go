@@ -0,0 +1,74 @@
+package uber
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/receipt"
+	"google.golang.org/api/gmail/v1"
+)
+
+var (
+	// trips and Uber Eats orders share the receipt layout; a trip's fare
+	// is its only item
+	layout = receipt.Layout{
+		Subtotal: []string{"Subtotal"},
+		Fees:     []string{"Booking Fee", "Service Fee", "Delivery Fee", "Small Order Fee", "Airport Surcharge", "Tolls, Surcharges, and Fees", "Wait Time"},
+		Discount: []string{"Promotion", "Promotions", "Uber One Savings", "Special Offers"},
+		Tax:      []string{"Taxes", "Tax", "Sales Tax"},
+		Tip:      []string{"Tip"},
+		Total:    []string{"Total", "Total Charged"},
+		Ignore:   []string{"Uber Cash", "Amount Charged"},
+	}
+	// "Your Tuesday evening order with Chipotle"
+	expEats = regexp.MustCompile(`(?i)\border (?:with|from) (.+?)\s*$`)
+)
+
+// ProviderUber reads Uber trip receipts and Uber Eats order receipts.
+// Account is the card or account they are paid with.
+type ProviderUber struct {
+	Account  string
+	Accounts receipt.Accounts
+}
+
+func (p *ProviderUber) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	body, err := email.HTML(msg)
+	if err != nil || body == nil {
+		return nil, err
+	}
+	fields, err := email.TableFields(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r, ok := receipt.Parse(fields, layout)
+	if !ok {
+		return nil, nil
+	}
+	r.Merchant = "Uber"
+	var subject string
+	for _, h := range msg.Payload.Headers {
+		if h.Name == "Subject" {
+			subject = h.Value
+			break
+		}
+	}
+	if m := expEats.FindStringSubmatch(subject); m != nil {
+		r.Merchant, r.Note = "Uber Eats", m[1]
+	}
+	r.Date = email.Sent(msg)
+	if r.Date.IsZero() {
+		return nil, errors.New("uber: email has no date")
+	}
+	t, err := r.Transaction(p.Account, p.Accounts)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (p *ProviderUber) GetAccount() string {
+	return p.Account
+}
@@ -0,0 +1,67 @@
+package uber
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/mikelu92/emailimport/pkg/receipt"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/gmail/v1"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Mon, 5 May 2025 19:42:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	sent := time.Date(2025, 5, 5, 19, 42, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		message  *gmail.Message
+		expected *ledger.Transaction
+	}{
+		{
+			name:    "Uber Eats order",
+			message: emailtest.HTML(t, dateHeader, "Your Monday evening order with Chipotle", "eats.html"),
+			expected: &ledger.Transaction{
+				Status: ledger.Cleared, Payee: "Uber Eats", Note: "Chipotle", Amount: "$33.41", Date: sent, Account: "liabilities:card",
+				Postings: []ledger.Posting{
+					{Account: "liabilities:card", Amount: "-$33.41"},
+					{Account: ledger.Uncategorised, Amount: "$11.25", Comment: "Burrito Bowl"},
+					{Account: ledger.Uncategorised, Amount: "$9.90", Comment: "2 × Chips & Guacamole"},
+					{Account: "expenses:fees:uber", Amount: "$2.49", Comment: "Delivery Fee"},
+					{Account: "expenses:fees:uber", Amount: "$3.17", Comment: "Service Fee"},
+					{Account: receipt.DefaultTaxAccount, Amount: "$1.90"},
+					{Account: receipt.DefaultTipAccount, Amount: "$4.70"},
+				},
+			},
+		},
+		{
+			name:    "trip",
+			message: emailtest.HTML(t, dateHeader, "Your Monday evening trip with Uber", "trip.html"),
+			expected: &ledger.Transaction{
+				Status: ledger.Cleared, Payee: "Uber", Amount: "$24.86", Date: sent, Account: "liabilities:card",
+				Postings: []ledger.Posting{
+					{Account: "liabilities:card", Amount: "-$24.86"},
+					{Account: ledger.Uncategorised, Amount: "$18.72", Comment: "Trip fare"},
+					{Account: "expenses:fees:uber", Amount: "$2.64", Comment: "Booking Fee"},
+					{Account: receipt.DefaultTipAccount, Amount: "$3.50"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ProviderUber{Account: "liabilities:card", Accounts: receipt.Accounts{Fee: "expenses:fees:uber"}}
+			result, err := p.GetTransaction(tc.message)
+			assert.NoError(t, err)
+			if result != nil {
+				result.Date = result.Date.UTC()
+			}
+			assert.Equal(t, tc.expected, result)
+			assert.NoError(t, result.Check())
+		})
+	}
+}
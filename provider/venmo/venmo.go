@@ -0,0 +1,106 @@
+package venmo
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+const amt = `(?P<amt>\$\d{1,3}(?:,\d{3})*\.\d{2})`
+
+// alert is a Venmo payment email, recognised by its subject, which the body
+// repeats as a headline followed by the payment note.
+type alert struct {
+	exp     *regexp.Regexp
+	receive bool
+}
+
+var (
+	alerts []alert
+	expID  *regexp.Regexp
+	// expNoteStop matches the lines following the headline that aren't a
+	// note, for payments sent without one
+	expNoteStop *regexp.Regexp
+)
+
+func init() {
+	alerts = []alert{
+		{exp: regexp.MustCompile(`^You paid (?P<payee>.+?) ` + amt + `$`)},
+		{exp: regexp.MustCompile(`^You completed (?P<payee>.+?)'s (?:charge )?request for ` + amt + `$`)},
+		{exp: regexp.MustCompile(`^(?P<payee>.+?) paid you ` + amt + `$`), receive: true},
+		{exp: regexp.MustCompile(`^(?P<payee>.+?) paid your ` + amt + ` (?:charge )?request$`), receive: true},
+	}
+	expID = regexp.MustCompile(`(?i)(?:Payment|Transaction) ID:?\s*(\d+)`)
+	expNoteStop = regexp.MustCompile(`(?i)^(?:payment id|transaction id|transfer date|date|see transaction|like|comment|money credited|money debited)\b|\$\d`)
+}
+
+type ProviderVenmo struct {
+	Account string
+}
+
+func (p *ProviderVenmo) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	var subject string
+	for _, h := range msg.Payload.Headers {
+		if h.Name == "Subject" {
+			subject = strings.TrimSpace(h.Value)
+			break
+		}
+	}
+	for _, a := range alerts {
+		match := a.exp.FindStringSubmatch(subject)
+		if len(match) == 0 {
+			continue
+		}
+		// Venmo only emails about completed payments
+		t := ledger.Transaction{Account: p.Account, IsReceive: a.receive, Status: ledger.Cleared}
+		for i, name := range a.exp.SubexpNames() {
+			switch name {
+			case "amt":
+				t.Amount = match[i]
+			case "payee":
+				t.Payee = match[i]
+			}
+		}
+		text := email.Text(msg)
+		t.Note = note(text, a.exp)
+		if m := expID.FindStringSubmatch(text); len(m) != 0 {
+			t.ID = m[1]
+		}
+		t.Date = email.Sent(msg)
+		if t.Date.IsZero() {
+			return nil, errors.New("venmo: email has no date")
+		}
+		return &t, nil
+	}
+	return nil, nil
+}
+
+// note returns the line following the headline in the body, skipping the
+// subject on the first line of text.
+func note(text string, headline *regexp.Regexp) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if !headline.MatchString(strings.TrimSpace(lines[i])) {
+			continue
+		}
+		for _, l := range lines[i+1:] {
+			l = strings.TrimSpace(l)
+			if l == "" {
+				continue
+			}
+			if expNoteStop.MatchString(l) {
+				return ""
+			}
+			return l
+		}
+	}
+	return ""
+}
+
+func (p *ProviderVenmo) GetAccount() string {
+	return p.Account
+}
@@ -0,0 +1,74 @@
+package venmo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/gmail/v1"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Tue, 6 May 2025 08:15:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	sent := time.Date(2025, 5, 6, 8, 15, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		message  *gmail.Message
+		expected *ledger.Transaction
+	}{
+		{
+			name: "you paid",
+			message: emailtest.Text(dateHeader, "You paid Jane Doe $25.00", `You paid Jane Doe $25.00
+
+Pizza night 🍕
+
+Transfer Date and Amount:
+May 05, 2025 PDT · $25.00
+Payment ID: 4123456789012345678`),
+			expected: &ledger.Transaction{
+				Account: "Venmo", Status: ledger.Cleared, Payee: "Jane Doe", Amount: "$25.00",
+				Note: "Pizza night 🍕", ID: "4123456789012345678", Date: sent,
+			},
+		},
+		{
+			name: "paid you",
+			message: emailtest.Text(dateHeader, "John Smith paid you $1,200.00", `John Smith paid you $1,200.00
+Rent
+Money credited to your Venmo account.
+Transaction ID 4223456789012345678`),
+			expected: &ledger.Transaction{
+				Account: "Venmo", Status: ledger.Cleared, Payee: "John Smith", Amount: "$1,200.00", IsReceive: true,
+				Note: "Rent", ID: "4223456789012345678", Date: sent,
+			},
+		},
+		{
+			name: "completed charge request without a note",
+			message: emailtest.Text(dateHeader, "You completed Jane Doe's charge request for $8.00", `You completed Jane Doe's charge request for $8.00
+Payment ID: 4323456789012345678`),
+			expected: &ledger.Transaction{
+				Account: "Venmo", Status: ledger.Cleared, Payee: "Jane Doe", Amount: "$8.00",
+				ID: "4323456789012345678", Date: sent,
+			},
+		},
+		{
+			name:    "request is not a payment",
+			message: emailtest.Text(dateHeader, "Jane Doe requests $8.00", "Jane Doe requests $8.00"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ProviderVenmo{Account: "Venmo"}
+			result, err := p.GetTransaction(tc.message)
+			assert.NoError(t, err)
+			if result != nil {
+				result.Date = result.Date.UTC()
+			}
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
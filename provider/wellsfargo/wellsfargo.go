@@ -0,0 +1,36 @@
+package wellsfargo
+
+import (
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+// alerts are Wells Fargo email templates: a subject containing one of the
+// phrases and a table of details such as "Purchase amount" and "Merchant
+// detail". The amount label tells the templates apart when the subject is
+// generic, e.g. "Wells Fargo card alert".
+var alerts = email.Alerts{
+	Payee:   []string{"Merchant detail", "Merchant", "Merchant name", "From"},
+	Date:    []string{"Date", "Transaction date", "Payment date", "Deposit date"},
+	Layouts: []string{"01/02/2006", "January 2, 2006", "Jan 2, 2006"},
+	Templates: []email.Alert{
+		{Subject: []string{"credit", "refund"}, Amount: []string{"Credit amount", "Refund amount"}, Receive: true, Status: ledger.Cleared},
+		{Subject: []string{"payment"}, Amount: []string{"Payment amount"}, Receive: true, Status: ledger.Cleared, Payee: "Wells Fargo card payment"},
+		{Subject: []string{"deposit"}, Amount: []string{"Deposit amount"}, Receive: true, Status: ledger.Cleared, Payee: "Deposit"},
+		{Subject: []string{"withdrawal"}, Amount: []string{"Withdrawal amount"}, Status: ledger.Cleared, Payee: "Withdrawal"},
+		{Subject: []string{"purchase", "transaction", "card alert"}, Amount: []string{"Purchase amount", "Transaction amount", "Amount"}, Status: ledger.Pending},
+	},
+}
+
+type ProviderWellsFargo struct {
+	Account string
+}
+
+func (p *ProviderWellsFargo) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	return alerts.FromTable(msg, p.Account)
+}
+
+func (p *ProviderWellsFargo) GetAccount() string {
+	return p.Account
+}
@@ -0,0 +1,55 @@
+package wellsfargo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/gmail/v1"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Tue, 6 May 2025 08:15:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	testCases := []struct {
+		name     string
+		message  *gmail.Message
+		expected *ledger.Transaction
+	}{
+		{
+			name:    "purchase over the amount set",
+			message: emailtest.HTML(t, dateHeader, "Wells Fargo card purchase alert", "purchase.html"),
+			expected: &ledger.Transaction{
+				Account: "WellsFargo", Status: ledger.Pending, Payee: "TRADER JOE S #552 SAN FRANCISCO CA", Amount: "$86.10",
+				Date: time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "direct deposit without a date",
+			message: emailtest.HTML(t, dateHeader, "Direct deposit alert", "deposit.html"),
+			expected: &ledger.Transaction{
+				Account: "WellsFargo", Status: ledger.Cleared, Payee: "ACME CORP PAYROLL", Amount: "$2,345.67", IsReceive: true,
+				Date: time.Date(2025, 5, 6, 8, 15, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "unrelated subject",
+			message: emailtest.HTML(t, dateHeader, "Your statement is ready", "purchase.html"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ProviderWellsFargo{Account: "WellsFargo"}
+			result, err := p.GetTransaction(tc.message)
+			assert.NoError(t, err)
+			if result != nil {
+				result.Date = result.Date.UTC()
+			}
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package zelle
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"google.golang.org/api/gmail/v1"
+)
+
+const amt = `(?P<amt>\$\d{1,3}(?:,\d{3})*\.\d{2})`
+
+// alert is a Zelle notification, recognised by its subject. Zelle emails
+// directly for banks that don't send their own alerts, see the chase
+// provider for those that do.
+type alert struct {
+	exp     *regexp.Regexp
+	receive bool
+}
+
+var (
+	alerts     []alert
+	expMemo    *regexp.Regexp
+	expID      *regexp.Regexp
+	expPending *regexp.Regexp
+)
+
+func init() {
+	alerts = []alert{
+		{exp: regexp.MustCompile(`^You(?:'ve| have)? sent ` + amt + ` to (?P<payee>.+?)(?: with Zelle®?)?$`)},
+		{exp: regexp.MustCompile(`^You(?:'ve| have)? received ` + amt + ` from (?P<payee>.+?)(?: with Zelle®?)?$`), receive: true},
+		{exp: regexp.MustCompile(`^(?P<payee>.+?) sent you ` + amt + `(?: with Zelle®?)?$`), receive: true},
+	}
+	expMemo = regexp.MustCompile(`(?im)^\s*(?:Memo|Message|Note):[ \t]*(.+?)\s*$`)
+	// the label must be followed by "number", "ID", "#" or a colon, or
+	// "transaction details" would be read as an ID
+	expID = regexp.MustCompile(`(?i)(?:Confirmation|Transaction|Reference)(?:(?: number| ID| #):?|:)\s*([A-Za-z0-9]{6,})`)
+	// payments to recipients who haven't enrolled yet only move money once
+	// they do
+	expPending = regexp.MustCompile(`(?i)\b(?:is pending|is on hold|waiting for .+? to enroll|(?:hasn't|has not|isn't|is not) (?:yet )?enrolled)\b`)
+}
+
+type ProviderZelle struct {
+	Account string
+}
+
+func (p *ProviderZelle) GetTransaction(msg *gmail.Message) (*ledger.Transaction, error) {
+	var subject string
+	for _, h := range msg.Payload.Headers {
+		if h.Name == "Subject" {
+			subject = strings.TrimSpace(h.Value)
+			break
+		}
+	}
+	for _, a := range alerts {
+		match := a.exp.FindStringSubmatch(subject)
+		if len(match) == 0 {
+			continue
+		}
+		text := email.Text(msg)
+		if !a.receive && expPending.MatchString(text) {
+			return nil, email.ErrNoTransaction
+		}
+		t := ledger.Transaction{Account: p.Account, IsReceive: a.receive, Status: ledger.Cleared}
+		for i, name := range a.exp.SubexpNames() {
+			switch name {
+			case "amt":
+				t.Amount = match[i]
+			case "payee":
+				t.Payee = match[i]
+			}
+		}
+		if m := expMemo.FindStringSubmatch(text); len(m) != 0 {
+			t.Note = m[1]
+		}
+		if m := expID.FindStringSubmatch(text); len(m) != 0 {
+			t.ID = m[1]
+		}
+		t.Date = email.Sent(msg)
+		if t.Date.IsZero() {
+			return nil, errors.New("zelle: email has no date")
+		}
+		return &t, nil
+	}
+	return nil, nil
+}
+
+func (p *ProviderZelle) GetAccount() string {
+	return p.Account
+}
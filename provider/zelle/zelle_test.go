@@ -0,0 +1,82 @@
+package zelle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikelu92/emailimport/pkg/email"
+	"github.com/mikelu92/emailimport/pkg/email/emailtest"
+	"github.com/mikelu92/emailimport/pkg/ledger"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/gmail/v1"
+)
+
+// dateHeader is the Date header of every test message.
+const dateHeader = "Tue, 6 May 2025 08:15:00 +0000"
+
+func TestGetTransaction(t *testing.T) {
+	sent := time.Date(2025, 5, 6, 8, 15, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		message  *gmail.Message
+		expected *ledger.Transaction
+		err      error
+	}{
+		{
+			name: "sent",
+			message: emailtest.Text(dateHeader, "You sent $150.00 to Jane Doe", `You sent $150.00 to Jane Doe.
+Memo: May rent share
+Confirmation number: QX7RT52KLM`),
+			expected: &ledger.Transaction{
+				Account: "Zelle", Status: ledger.Cleared, Payee: "Jane Doe", Amount: "$150.00",
+				Note: "May rent share", ID: "QX7RT52KLM", Date: sent,
+			},
+		},
+		{
+			name:    "sent you",
+			message: emailtest.Text(dateHeader, "John Smith sent you $40.00 with Zelle®", "John Smith sent you $40.00.\nMessage: concert tickets"),
+			expected: &ledger.Transaction{
+				Account: "Zelle", Status: ledger.Cleared, Payee: "John Smith", Amount: "$40.00", IsReceive: true,
+				Note: "concert tickets", Date: sent,
+			},
+		},
+		{
+			name:    "received",
+			message: emailtest.Text(dateHeader, "You received $40.00 from John Smith", "The money is in your account."),
+			expected: &ledger.Transaction{
+				Account: "Zelle", Status: ledger.Cleared, Payee: "John Smith", Amount: "$40.00", IsReceive: true, Date: sent,
+			},
+		},
+		{
+			name:    "no confirmation number",
+			message: emailtest.Text(dateHeader, "You sent $12.00 to Jane Doe", "You sent $12.00 to Jane Doe.\nView transaction details in the app."),
+			expected: &ledger.Transaction{
+				Account: "Zelle", Status: ledger.Cleared, Payee: "Jane Doe", Amount: "$12.00", Date: sent,
+			},
+		},
+		{
+			name:    "confirmation after a colon",
+			message: emailtest.Text(dateHeader, "You sent $12.00 to Jane Doe", "You sent $12.00 to Jane Doe.\nConfirmation: QX7RT52KLM"),
+			expected: &ledger.Transaction{
+				Account: "Zelle", Status: ledger.Cleared, Payee: "Jane Doe", Amount: "$12.00", ID: "QX7RT52KLM", Date: sent,
+			},
+		},
+		{
+			name:    "recipient not enrolled",
+			message: emailtest.Text(dateHeader, "You sent $20.00 to Jane Doe", "Your payment is pending. We're waiting for Jane Doe to enroll with Zelle."),
+			err:     email.ErrNoTransaction,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ProviderZelle{Account: "Zelle"}
+			result, err := p.GetTransaction(tc.message)
+			assert.ErrorIs(t, err, tc.err)
+			if result != nil {
+				result.Date = result.Date.UTC()
+			}
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}